@@ -0,0 +1,248 @@
+package sbcgostructgen
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// The layout of a single C struct field as it was generated.
+	FieldLayout struct {
+		Name   string  `json:"name"`
+		Type   string  `json:"type"`
+		Offset uintptr `json:"offset"`
+		Size   uintptr `json:"size"`
+	}
+
+	// The layout of a single generated C struct.
+	StructLayout struct {
+		Size   uintptr       `json:"size"`
+		Fields []FieldLayout `json:"fields"`
+	}
+
+	// A snapshot of the layout of every struct that was added to a
+	// [CGoStructGen]. Snapshots can be saved with
+	// [CGoStructGen.WriteLayoutSnapshot] and later compared against with
+	// [CGoStructGen.CheckABICompat] to determine if previously compiled C code
+	// can still be used with the current struct definitions.
+	LayoutSnapshot struct {
+		// Maps C struct names to their layouts.
+		Structs map[string]StructLayout `json:"structs"`
+	}
+)
+
+var (
+	StructRemovedErr      = errors.New("Struct removed")
+	StructShrunkErr       = errors.New("Struct shrunk")
+	FieldRemovedErr       = errors.New("Field removed")
+	FieldReorderedErr     = errors.New("Field reordered")
+	FieldTypeChangedErr   = errors.New("Field type changed")
+	FieldOffsetChangedErr = errors.New("Field offset changed")
+	FieldSizeChangedErr   = errors.New("Field size changed")
+)
+
+// Returns a snapshot of the layout of all of the structs that were previously
+// added through calls to [GenerateFor]. The fields of anonymous members, see
+// [EmbeddedFieldsAnonymous], are listed as fields of the struct itself.
+func (c *CGoStructGen) Layout() LayoutSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+
+	rv := LayoutSnapshot{Structs: map[string]StructLayout{}}
+	for structName, structFields := range c.structs {
+		layout := StructLayout{Fields: fieldLayouts(nil, structFields)}
+		if refType, ok := c.types[structName]; ok {
+			layout.Size = refType.Size()
+		}
+		rv.Structs[structName] = layout
+	}
+	return rv
+}
+
+// Appends the layouts of the supplied fields to rv. The fields of anonymous
+// members are appended in place of the member because C code refers to them
+// by their own names.
+func fieldLayouts(rv []FieldLayout, fields []structField) []FieldLayout {
+	for _, iterField := range fields {
+		if iterField.name == "" && iterField.inline != nil {
+			rv = fieldLayouts(rv, iterField.inline)
+			continue
+		}
+		rv = append(rv, FieldLayout{
+			Name:   iterField.name,
+			Type:   iterField.typeString(),
+			Offset: iterField.offset,
+			Size:   iterField.size,
+		})
+	}
+	return rv
+}
+
+// Writes the current layout snapshot, as returned by [CGoStructGen.Layout], to
+// the specified file as JSON.
+func (c *CGoStructGen) WriteLayoutSnapshot(file string) error {
+	var err error
+	var data []byte
+
+	data, err = json.MarshalIndent(c.Layout(), "", "\t")
+	if err != nil {
+		goto errExit
+	}
+	err = os.WriteFile(file, append(data, '\n'), 0644)
+
+errExit:
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+// Reads a layout snapshot that was previously written with
+// [CGoStructGen.WriteLayoutSnapshot].
+func ReadLayoutSnapshot(file string) (LayoutSnapshot, error) {
+	var rv LayoutSnapshot
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return rv, err
+	}
+	err = json.Unmarshal(data, &rv)
+	return rv, err
+}
+
+// Compares the layout of all of the structs that were previously added through
+// calls to [GenerateFor] against the supplied snapshot. Any change that would
+// break C code compiled against the snapshot will be returned as an error. The
+// following changes are considered breaking:
+//   - a struct that is in the snapshot is no longer generated ([StructRemovedErr])
+//   - a struct is smaller than it was ([StructShrunkErr])
+//   - a field was removed ([FieldRemovedErr])
+//   - a field was moved relative to the other fields that are in both the
+//     snapshot and the current layout ([FieldReorderedErr])
+//   - a field has a different C type ([FieldTypeChangedErr])
+//   - a field starts at a different offset ([FieldOffsetChangedErr])
+//   - a field has a different size ([FieldSizeChangedErr])
+//
+// Fields appended to the end of a struct and entirely new structs are
+// considered compatible. Reserved fields, which only fill space, are ignored.
+// All breaking changes are reported, each one wrapped with the struct and field
+// it applies to, so [errors.Is] can be used to check for specific kinds of
+// changes.
+func (c *CGoStructGen) CheckABICompat(prev LayoutSnapshot) error {
+	var err error
+	cur := c.Layout()
+
+	structNames := slices.Collect(maps.Keys(prev.Structs))
+	slices.Sort(structNames)
+	for _, structName := range structNames {
+		err = sberr.AppendError(err, checkStructABICompat(
+			structName, prev.Structs[structName], cur.Structs,
+		))
+	}
+
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+func checkStructABICompat(
+	structName string,
+	prev StructLayout,
+	curStructs map[string]StructLayout,
+) error {
+	cur, ok := curStructs[structName]
+	if !ok {
+		return sberr.Wrap(StructRemovedErr, "Struct %s", structName)
+	}
+
+	var err error
+	if cur.Size < prev.Size {
+		err = sberr.AppendError(err, sberr.Wrap(
+			StructShrunkErr, "Struct %s, prev size %d, new size %d",
+			structName, prev.Size, cur.Size,
+		))
+	}
+
+	prevFields := abiFields(prev.Fields)
+	curFields := abiFields(cur.Fields)
+	// Only the order of the fields that are in both layouts is compared, so
+	// removing or inserting a field does not also report the fields after it
+	// as reordered
+	prevOrder := commonFieldNames(prevFields, curFields)
+	curOrder := commonFieldNames(curFields, prevFields)
+	for _, prevField := range prevFields {
+		j := slices.IndexFunc(curFields, func(f FieldLayout) bool {
+			return f.Name == prevField.Name
+		})
+		if j < 0 {
+			err = sberr.AppendError(err, sberr.Wrap(
+				FieldRemovedErr, "Struct %s, field %s",
+				structName, prevField.Name,
+			))
+			continue
+		}
+
+		curField := curFields[j]
+		i := slices.Index(prevOrder, prevField.Name)
+		if k := slices.Index(curOrder, prevField.Name); i != k {
+			err = sberr.AppendError(err, sberr.Wrap(
+				FieldReorderedErr,
+				"Struct %s, field %s, prev position %d, new position %d",
+				structName, prevField.Name, i, k,
+			))
+		}
+		if curField.Type != prevField.Type {
+			err = sberr.AppendError(err, sberr.Wrap(
+				FieldTypeChangedErr,
+				"Struct %s, field %s, prev type %s, new type %s",
+				structName, prevField.Name, prevField.Type, curField.Type,
+			))
+		}
+		if curField.Offset != prevField.Offset {
+			err = sberr.AppendError(err, sberr.Wrap(
+				FieldOffsetChangedErr,
+				"Struct %s, field %s, prev offset %d, new offset %d",
+				structName, prevField.Name, prevField.Offset, curField.Offset,
+			))
+		}
+		if curField.Size != prevField.Size {
+			err = sberr.AppendError(err, sberr.Wrap(
+				FieldSizeChangedErr,
+				"Struct %s, field %s, prev size %d, new size %d",
+				structName, prevField.Name, prevField.Size, curField.Size,
+			))
+		}
+	}
+	return err
+}
+
+// Returns the fields that are part of the ABI of a struct. Reserved fields
+// only fill space, they can be added or removed as long as the offsets of the
+// other fields do not change.
+func abiFields(fields []FieldLayout) []FieldLayout {
+	return slices.DeleteFunc(slices.Clone(fields), func(f FieldLayout) bool {
+		return strings.HasPrefix(f.Name, "_reserved_")
+	})
+}
+
+// Returns the names of the fields in l that are also in r, in the order they
+// appear in l.
+func commonFieldNames(l []FieldLayout, r []FieldLayout) []string {
+	rv := make([]string, 0, len(l))
+	for _, f := range l {
+		if slices.ContainsFunc(r, func(o FieldLayout) bool {
+			return o.Name == f.Name
+		}) {
+			rv = append(rv, f.Name)
+		}
+	}
+	return rv
+}
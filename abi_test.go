@@ -0,0 +1,258 @@
+package sbcgostructgen
+
+import (
+	"errors"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestLayout(t *testing.T) {
+	type s2 struct{ f1 int16 }
+	type s1 struct {
+		f1 int8
+		f2 [2]uint32
+		f3 *s2
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+
+	layout := res.Layout()
+	sbtest.Eq(t, 2, len(layout.Structs))
+	sbtest.Eq(t, 24, layout.Structs["s1"].Size)
+	sbtest.SlicesMatch(t,
+		[]FieldLayout{
+			{Name: "f1", Type: "int8_t", Offset: 0, Size: 1},
+			{Name: "f2", Type: "uint32_t[2]", Offset: 4, Size: 8},
+			{Name: "f3", Type: "s2_t*", Offset: 16, Size: 8},
+		},
+		layout.Structs["s1"].Fields,
+	)
+	sbtest.Eq(t, 2, layout.Structs["s2"].Size)
+	sbtest.SlicesMatch(t,
+		[]FieldLayout{{Name: "f1", Type: "int16_t", Offset: 0, Size: 2}},
+		layout.Structs["s2"].Fields,
+	)
+}
+
+func TestLayoutSnapshotRoundTrip(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		f2 [2]uint32
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteLayoutSnapshot("./bs/testData/layoutSnapshot.json")
+	sbtest.Nil(t, err)
+
+	snapshot, err := ReadLayoutSnapshot("./bs/testData/layoutSnapshot.json")
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, 1, len(snapshot.Structs))
+	sbtest.Eq(t, res.Layout().Structs["s1"].Size, snapshot.Structs["s1"].Size)
+	sbtest.SlicesMatch(t,
+		res.Layout().Structs["s1"].Fields, snapshot.Structs["s1"].Fields,
+	)
+	sbtest.Nil(t, res.CheckABICompat(snapshot))
+}
+
+func snapshotFor[T any](t *testing.T) LayoutSnapshot {
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[T](res))
+	return res.Layout()
+}
+
+func TestCheckABICompatAppendedField(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct {
+			f1 int32
+			f2 int32
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	type s1 struct {
+		f1 int32
+		f2 int32
+		f3 int64
+	}
+	type s2 struct{ f1 int8 }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.Nil(t, res.CheckABICompat(prev))
+}
+
+func TestCheckABICompatStructRemoved(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct{ f1 int32 }
+		return snapshotFor[s1](t)
+	}()
+
+	type s2 struct{ f1 int32 }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.ContainsError(t, StructRemovedErr, res.CheckABICompat(prev))
+}
+
+func TestCheckABICompatFieldRemoved(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct {
+			f1 int32
+			f2 int32
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	type s1 struct{ f1 int32 }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	err := res.CheckABICompat(prev)
+	sbtest.ContainsError(t, FieldRemovedErr, err)
+	sbtest.ContainsError(t, StructShrunkErr, err)
+}
+
+func TestCheckABICompatFieldReordered(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct {
+			f1 int32
+			f2 int32
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	type s1 struct {
+		f2 int32
+		f1 int32
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	err := res.CheckABICompat(prev)
+	sbtest.ContainsError(t, FieldReorderedErr, err)
+	sbtest.ContainsError(t, FieldOffsetChangedErr, err)
+}
+
+func TestCheckABICompatMiddleFieldRemoved(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct {
+			f1 int32
+			f2 int32
+			f3 int32
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	type s1 struct {
+		f1 int32
+		f3 int32
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	err := res.CheckABICompat(prev)
+	sbtest.ContainsError(t, FieldRemovedErr, err)
+	sbtest.ContainsError(t, FieldOffsetChangedErr, err)
+	sbtest.False(t, errors.Is(err, FieldReorderedErr))
+}
+
+func TestCheckABICompatReservedFields(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct {
+			f1 int32
+			_  int32
+			f2 int32
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	// The reserved space was given to a new field
+	type s1 struct {
+		f1 int32
+		f3 int32
+		f2 int32
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Nil(t, res.CheckABICompat(prev))
+	sbtest.Nil(t, res.CheckABICompat(res.Layout()))
+}
+
+func TestCheckABICompatFieldTypeChanged(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s1 struct {
+			f1 int32
+			f2 int32
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	type s1 struct {
+		f1 uint32
+		f2 int32
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	err := res.CheckABICompat(prev)
+	sbtest.ContainsError(t, FieldTypeChangedErr, err)
+}
+
+func TestCheckABICompatFieldSizeChanged(t *testing.T) {
+	prev := func() LayoutSnapshot {
+		type s2 struct{ f1 int32 }
+		type s1 struct {
+			f1 [2]s2
+			f2 int64
+		}
+		return snapshotFor[s1](t)
+	}()
+
+	// Appending a field to s2 is compatible for s2 but changes the stride of
+	// the array in s1
+	type s2 struct {
+		f1 int32
+		f2 int32
+	}
+	type s1 struct {
+		f1 [2]s2
+		f2 int64
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	err := res.CheckABICompat(prev)
+	sbtest.ContainsError(t, FieldSizeChangedErr, err)
+	sbtest.ContainsError(t, FieldOffsetChangedErr, err)
+}
+
+func TestCheckABICompatAnonymousMembers(t *testing.T) {
+	type Base struct{ ID int32 }
+	type Other struct{ Val int64 }
+	type s1 struct {
+		f1 int8
+		Base
+		Other
+	}
+	res := New(Opts{EmbeddedFields: EmbeddedFieldsAnonymous})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	layout := res.Layout()
+	sbtest.SlicesMatch(t,
+		[]FieldLayout{
+			{Name: "f1", Type: "int8_t", Offset: 0, Size: 1},
+			{Name: "ID", Type: "int32_t", Offset: 4, Size: 4},
+			{Name: "Val", Type: "int64_t", Offset: 8, Size: 8},
+		},
+		layout.Structs["s1"].Fields,
+	)
+	sbtest.Nil(t, res.CheckABICompat(layout))
+
+	// Moving a field out of the anonymous member keeps its name and offset
+	type s2 struct {
+		f1 int8
+		ID int32
+		Other
+	}
+	res = New(Opts{EmbeddedFields: EmbeddedFieldsAnonymous})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	layout.Structs["s2"] = layout.Structs["s1"]
+	delete(layout.Structs, "s1")
+	sbtest.Nil(t, res.CheckABICompat(layout))
+}
@@ -0,0 +1,74 @@
+package sbcgostructgen
+
+import (
+	"reflect"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// Describes how anonymous struct types are written in C. See
+	// [Opts.AnonymousStructs].
+	AnonymousStructMode int
+)
+
+const (
+	// Anonymous structs are rejected with an [AnonymousNameErr].
+	AnonymousStructsReject AnonymousStructMode = iota
+	// Anonymous structs are written as named C structs. The name is made from
+	// the name of the parent C struct and the C name of the field, i.e. the
+	// `Limits` field in `Parent` is written as a `Parent_Limits_t`. The
+	// synthesized names can be renamed with [Opts.StructRename].
+	AnonymousStructsNamed
+	// Anonymous structs are written inline as anonymous C structs, i.e.
+	// `struct{ int32_t Min; int32_t Max; } Limits;`. Slices of anonymous
+	// structs cannot be written inline.
+	AnonymousStructsInline
+)
+
+// Returns the name that an anonymous struct in the supplied field of the
+// supplied parent struct is given.
+func anonStructName(parent string, fieldName string) string {
+	if parent == "" {
+		return ""
+	}
+	return parent + "_" + fieldName
+}
+
+// Returns the C name of the supplied struct type and true if the struct
+// should be written inline. The anonName argument is the name the struct is
+// given if it is anonymous and [Opts.AnonymousStructs] is
+// [AnonymousStructsNamed]. The name of inline structs is used to name the
+// array length macros and any anonymous structs nested within them.
+func (c *CGoStructGen) cStructName(
+	refType reflect.Type,
+	anonName string,
+) (string, bool, error) {
+	name := refType.Name()
+	inline := false
+	if name == "" {
+		switch {
+		case c.opts.AnonymousStructs == AnonymousStructsReject:
+			return "", false, sberr.Wrap(
+				AnonymousNameErr,
+				"Anonymous structs are not supported, add a name or set an anonymous struct mode",
+			)
+		case anonName == "":
+			return "", false, sberr.Wrap(
+				AnonymousNameErr,
+				"Anonymous structs must be fields of another struct",
+			)
+		case c.opts.AnonymousStructs == AnonymousStructsInline:
+			inline = true
+		}
+		name = anonName
+	}
+	if rename, ok := c.opts.StructRename[name]; ok {
+		name = rename
+	}
+	name = c.cTypeIdent(c.typeCase(name), !inline)
+	if err := c.checkTypeIdent(name, !inline); err != nil {
+		return "", false, err
+	}
+	return name, inline, nil
+}
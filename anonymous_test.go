@@ -0,0 +1,221 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForAnonymousReject(t *testing.T) {
+	type s1 struct{ f1 struct{ f1 int32 } }
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, AnonymousNameErr, err)
+
+	err = GenerateFor[struct{ f1 int32 }](
+		New(Opts{AnonymousStructs: AnonymousStructsNamed}),
+	)
+	sbtest.ContainsError(t, AnonymousNameErr, err)
+}
+
+func TestGenerateForAnonymousNamed(t *testing.T) {
+	type Parent struct {
+		Limits struct{ Min, Max int32 }
+		Ranges [2]struct {
+			Lo    int8
+			Inner struct{ V uint16 }
+		}
+		Other *struct{ X float32 } `cgo:"name=other"`
+	}
+	res := New(Opts{AnonymousStructs: AnonymousStructsNamed})
+	sbtest.Nil(t, GenerateFor[Parent](res))
+
+	sbtest.Eq(t, 5, len(res.structs))
+	fields := res.structs["Parent"]
+	sbtest.Eq(t, "Parent_Limits_t Limits", fields[0].String())
+	sbtest.Eq(t, "Parent_Limits", fields[0].structRef)
+	sbtest.Eq(t, "Parent_Ranges_t Ranges[2]", fields[1].String())
+	sbtest.Eq(t, "Parent_other_t* other", fields[2].String())
+	sbtest.Eq(t, 2, len(res.structs["Parent_Limits"]))
+	sbtest.Eq(t,
+		"Parent_Ranges_Inner_t Inner", res.structs["Parent_Ranges"][1].String(),
+	)
+	sbtest.Eq(t, "uint16_t V", res.structs["Parent_Ranges_Inner"][0].String())
+	sbtest.Eq(t, "float_t X", res.structs["Parent_other"][0].String())
+}
+
+func TestGenerateForAnonymousNamedRename(t *testing.T) {
+	type Parent struct{ Limits struct{ Min, Max int32 } }
+	res := New(Opts{
+		AnonymousStructs: AnonymousStructsNamed,
+		StructRename:     map[string]string{"Parent_Limits": "Limits"},
+	})
+	sbtest.Nil(t, GenerateFor[Parent](res))
+	sbtest.Eq(t, "Limits_t Limits", res.structs["Parent"][0].String())
+	sbtest.Eq(t, 2, len(res.structs["Limits"]))
+}
+
+func TestGenerateForAnonymousNamedCollision(t *testing.T) {
+	type Parent_Limits struct{ Min int8 }
+	type Parent struct {
+		Limits struct{ Min, Max int32 }
+		Other  Parent_Limits
+	}
+	res := New(Opts{AnonymousStructs: AnonymousStructsNamed})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[Parent](res))
+
+	type Parent2 struct {
+		A   struct{ B struct{ V int8 } }
+		A_B struct{ V int8 }
+	}
+	res = New(Opts{AnonymousStructs: AnonymousStructsNamed})
+	sbtest.Nil(t, GenerateFor[Parent2](res))
+
+	type Parent3 struct {
+		A   struct{ B struct{ V int8 } }
+		A_B struct{ V int16 }
+	}
+	res = New(Opts{AnonymousStructs: AnonymousStructsNamed})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[Parent3](res))
+}
+
+func TestGenerateForAnonymousNamedSlice(t *testing.T) {
+	type Parent struct{ Items []struct{ V int32 } }
+	res := New(Opts{
+		AnonymousStructs: AnonymousStructsNamed,
+		SliceStructs:     true,
+	})
+	sbtest.Nil(t, GenerateFor[Parent](res))
+	sbtest.Eq(t, "Slice_Parent_Items_t Items", res.structs["Parent"][0].String())
+	sbtest.Eq(t,
+		"Parent_Items_t* data", res.structs["Slice_Parent_Items"][0].String(),
+	)
+}
+
+func TestGenerateForAnonymousInline(t *testing.T) {
+	type Parent struct {
+		Limits struct{ Min, Max int32 }
+		Ranges [2]struct {
+			Lo    int8
+			Inner struct{ V uint16 }
+		}
+	}
+	res := New(Opts{AnonymousStructs: AnonymousStructsInline})
+	sbtest.Nil(t, GenerateFor[Parent](res))
+
+	sbtest.Eq(t, 1, len(res.structs))
+	fields := res.structs["Parent"]
+	sbtest.Eq(t, "struct{ int32_t Min; int32_t Max; } Limits", fields[0].String())
+	sbtest.Eq(t,
+		"struct{ int8_t Lo; struct{ uint16_t V; } Inner; } Ranges[2]",
+		fields[1].String(),
+	)
+	sbtest.Eq(t, 8, fields[1].offset)
+}
+
+func TestGenerateForAnonymousInlineSlice(t *testing.T) {
+	type Parent struct{ Items []*struct{ V int32 } }
+	res := New(Opts{
+		AnonymousStructs: AnonymousStructsInline,
+		SliceStructs:     true,
+	})
+	sbtest.ContainsError(t, AnonymousNameErr, GenerateFor[Parent](res))
+}
+
+func TestWriteAnonymousStructs(t *testing.T) {
+	type Parent struct {
+		Limits struct{ Min, Max int32 }
+		Vals   [2]struct{ V [3]int8 }
+	}
+	for _, test := range []struct {
+		mode AnonymousStructMode
+		file string
+		exp  string
+	}{
+		{
+			mode: AnonymousStructsNamed,
+			file: "./bs/testData/anonymousNamed.h",
+			exp: `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Parent_Limits{
+		int32_t Min;
+		int32_t Max;
+	} Parent_Limits_t;
+
+	#define PARENT_VALS_V_LEN 3
+	typedef struct Parent_Vals{
+		int8_t V[PARENT_VALS_V_LEN];
+	} Parent_Vals_t;
+
+	#define PARENT_VALS_LEN 2
+	typedef struct Parent{
+		Parent_Limits_t Limits;
+		Parent_Vals_t Vals[PARENT_VALS_LEN];
+	} Parent_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`,
+		},
+		{
+			mode: AnonymousStructsInline,
+			file: "./bs/testData/anonymousInline.h",
+			exp: `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define PARENT_VALS_V_LEN 3
+	#define PARENT_VALS_LEN 2
+	typedef struct Parent{
+		struct{
+			int32_t Min;
+			int32_t Max;
+		} Limits;
+		struct{
+			int8_t V[PARENT_VALS_V_LEN];
+		} Vals[PARENT_VALS_LEN];
+	} Parent_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`,
+		},
+	} {
+		res := New(Opts{
+			AnonymousStructs:   test.mode,
+			EmitArrayLenMacros: true,
+		})
+		err := GenerateFor[Parent](res)
+		sbtest.Nil(t, err)
+		err = res.WriteTo(test.file, "HEADER_GUARD")
+		sbtest.Nil(t, err)
+
+		data, err := os.ReadFile(test.file)
+		sbtest.Nil(t, err)
+		sbtest.Eq(t, string(data), test.exp)
+	}
+}
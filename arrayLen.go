@@ -0,0 +1,143 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+const defaultArrayLenMacroPattern = "{STRUCT}_{FIELD}_LEN"
+
+// Returns the number of array dimensions the supplied type will have in C.
+// Arrays that are behind a pointer do not add a dimension.
+func arrayDims(refType reflect.Type) int {
+	rv := 0
+	for refType.Kind() == reflect.Array {
+		rv++
+		refType = refType.Elem()
+	}
+	return rv
+}
+
+// Returns the package qualified names of the Go constants that give the length
+// of each array dimension of the supplied field of the supplied struct type,
+// see [Opts.ArrayLenConstNames]. A constant is only used if its value matches
+// the length of the dimension.
+func (c *CGoStructGen) arrayLenConsts(
+	refType reflect.Type,
+	field reflect.StructField,
+) []string {
+	if !c.opts.ArrayLenConstNames {
+		return nil
+	}
+	lens := c.docs[refType].arrayLens[field.Name]
+	rv := make([]string, len(lens))
+	dim := field.Type
+	for i, l := range lens {
+		if dim.Kind() != reflect.Array {
+			break
+		}
+		if l.name != "" && l.val == int64(dim.Len()) {
+			rv[i] = refType.PkgPath() + "." + l.name
+		}
+		dim = dim.Elem()
+	}
+	return rv
+}
+
+// Returns the names of the array length macros for each dimension of the
+// supplied field. Dimensions that have a constant name use it, all others use
+// the [Opts.ArrayLenMacroPattern].
+func (c *CGoStructGen) arrayLenMacros(
+	structName string,
+	fieldName string,
+	dims int,
+	consts []string,
+) []string {
+	if dims == 0 {
+		return nil
+	}
+	pattern := c.opts.ArrayLenMacroPattern
+	if pattern == "" {
+		pattern = defaultArrayLenMacroPattern
+	}
+
+	tag := c.cTag(structName)
+	rv := make([]string, dims)
+	for i := range dims {
+		r := strings.NewReplacer(
+			"{STRUCT}", strings.ToUpper(tag),
+			"{FIELD}", strings.ToUpper(fieldName),
+			"{Struct}", tag,
+			"{Field}", fieldName,
+			"{DIM}", fmt.Sprint(i),
+		)
+		if i < len(consts) && consts[i] != "" {
+			rv[i] = consts[i][strings.LastIndex(consts[i], ".")+1:]
+			continue
+		}
+		rv[i] = r.Replace(pattern)
+		if i > 0 && !strings.Contains(pattern, "{DIM}") {
+			rv[i] = fmt.Sprintf("%s_%d", rv[i], i)
+		}
+	}
+	return rv
+}
+
+// Checks that the array length macros for the supplied field are valid C
+// identifiers and are not used by any other field or constant. Macros named
+// after a Go constant belong to that constant rather than to the field, so they
+// can be shared by every field the constant sizes.
+func (c *CGoStructGen) checkArrayLenMacros(
+	structName string,
+	fieldName string,
+	refType reflect.Type,
+	consts []string,
+) error {
+	if !c.opts.EmitArrayLenMacros {
+		return nil
+	}
+	for i, macro := range c.arrayLenMacros(
+		structName, fieldName, arrayDims(refType), consts,
+	) {
+		owner := structName + "." + fieldName
+		if i < len(consts) && consts[i] != "" {
+			owner = consts[i]
+		}
+		if !cIdentRegex.MatchString(macro) {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"The array length macro '%s' is not a valid C identifier",
+				macro,
+			)
+		}
+		if err := checkIdent(macro); err != nil {
+			return err
+		}
+		if _, ok := c.consts[macro]; ok {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The array length macro %s has the same name as a constant",
+				macro,
+			)
+		}
+		if other, ok := c.hashMacros[macro]; ok {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The array length macro %s has the same name as the layout hash macro of %s",
+				macro, other,
+			)
+		}
+		if other, ok := c.macros[macro]; ok && other != owner {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The array length macro %s is used by both %s and %s",
+				macro, other, owner,
+			)
+		}
+		c.macros[macro] = owner
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestArrayLenMacros(t *testing.T) {
+	res := New(Opts{})
+	sbtest.SlicesMatch(t, nil, res.arrayLenMacros("s1", "f1", 0, nil))
+	sbtest.SlicesMatch(t,
+		[]string{"S1_F1_LEN"}, res.arrayLenMacros("s1", "f1", 1, nil),
+	)
+	sbtest.SlicesMatch(t,
+		[]string{"S1_F1_LEN", "S1_F1_LEN_1", "S1_F1_LEN_2"},
+		res.arrayLenMacros("s1", "f1", 3, nil),
+	)
+
+	res = New(Opts{ArrayLenMacroPattern: "{Struct}_{Field}_DIM{DIM}"})
+	sbtest.SlicesMatch(t,
+		[]string{"s1_f1_DIM0", "s1_f1_DIM1"},
+		res.arrayLenMacros("s1", "f1", 2, nil),
+	)
+}
+
+const testMaxItems = 4
+
+type (
+	testConstLens struct {
+		f1 [testMaxItems]int32
+		f2 [testMaxItems][2]uint8
+		f3 [3]int16
+	}
+	testConstLensOther struct {
+		f1 [testMaxItems]int64
+	}
+)
+
+func TestGenerateForArrayLenConstNames(t *testing.T) {
+	res := New(Opts{EmitArrayLenMacros: true, ArrayLenConstNames: true})
+	sbtest.Nil(t, GenerateFor[testConstLens](res))
+	sbtest.Nil(t, GenerateFor[testConstLensOther](res))
+
+	fields := res.structs["testConstLens"]
+	sbtest.Eq(t, "int32_t f1[testMaxItems]", fields[0].String())
+	sbtest.Eq(t,
+		"uint8_t f2[testMaxItems][TESTCONSTLENS_F2_LEN_1]", fields[1].String(),
+	)
+	sbtest.Eq(t, "int16_t f3[TESTCONSTLENS_F3_LEN]", fields[2].String())
+	sbtest.Eq(t,
+		"int64_t f1[testMaxItems]",
+		res.structs["testConstLensOther"][0].String(),
+	)
+	sbtest.ContainsError(
+		t, DuplicateNameErr, GenerateConst(res, "testMaxItems", int32(4)),
+	)
+
+	// Types declared in a function do not use the constants of a package
+	// level type with the same name
+	{
+		type testConstLensOther struct {
+			f1 [9]int64
+		}
+		type testConstLens struct {
+			f1 [testMaxItems]int32
+		}
+		res := New(Opts{EmitArrayLenMacros: true, ArrayLenConstNames: true})
+		sbtest.Nil(t, GenerateFor[testConstLensOther](res))
+		sbtest.Nil(t, GenerateFor[testConstLens](res))
+		sbtest.Eq(t,
+			"int64_t f1[TESTCONSTLENSOTHER_F1_LEN]",
+			res.structs["testConstLensOther"][0].String(),
+		)
+		sbtest.Eq(t,
+			"int32_t f1[TESTCONSTLENS_F1_LEN]",
+			res.structs["testConstLens"][0].String(),
+		)
+	}
+
+	// Without the option the pattern is used for every dimension
+	res = New(Opts{EmitArrayLenMacros: true})
+	sbtest.Nil(t, GenerateFor[testConstLens](res))
+	sbtest.Eq(t,
+		"int32_t f1[TESTCONSTLENS_F1_LEN]",
+		res.structs["testConstLens"][0].String(),
+	)
+}
+
+func TestGenerateForArrayLenMacroCollision(t *testing.T) {
+	type a_b struct{ c [2]int32 }
+	type a struct {
+		b_c [3]int32
+		f1  a_b
+	}
+	res := New(Opts{EmitArrayLenMacros: true})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[a](res))
+
+	type s1 struct{ f1 [2]int32 }
+	res = New(Opts{EmitArrayLenMacros: true})
+	sbtest.Nil(t, GenerateConst(res, "S1_F1_LEN", int32(2)))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+}
+
+func TestGenerateForArrayLenMacroInvalid(t *testing.T) {
+	type s1 struct{ f1 [2]int32 }
+	res := New(Opts{
+		EmitArrayLenMacros:   true,
+		ArrayLenMacroPattern: "{STRUCT}-{FIELD}",
+	})
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](res))
+}
+
+func TestWriteArrayLenMacros(t *testing.T) {
+	type s2 struct {
+		f7 [5]uint32
+		f8 [2][3]int32 `cgo:"name=grid"`
+		f9 int8
+	}
+	res := New(Opts{EmitArrayLenMacros: true})
+	err := GenerateFor[s2](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/arrayLen.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/arrayLen.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define S2_F7_LEN 5
+	#define S2_GRID_LEN 2
+	#define S2_GRID_LEN_1 3
+	typedef struct s2{
+		uint32_t f7[S2_F7_LEN];
+		int32_t grid[S2_GRID_LEN][S2_GRID_LEN_1];
+		int8_t f9;
+	} s2_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestWriteArrayLenConstNames(t *testing.T) {
+	res := New(Opts{EmitArrayLenMacros: true, ArrayLenConstNames: true})
+	sbtest.Nil(t, GenerateFor[testConstLens](res))
+	sbtest.Nil(t, GenerateFor[testConstLensOther](res))
+	err := res.WriteTo("./bs/testData/arrayLenConstNames.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/arrayLenConstNames.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define testMaxItems 4
+	#define TESTCONSTLENS_F2_LEN_1 2
+	#define TESTCONSTLENS_F3_LEN 3
+	typedef struct testConstLens{
+		int32_t f1[testMaxItems];
+		uint8_t f2[testMaxItems][TESTCONSTLENS_F2_LEN_1];
+		int16_t f3[TESTCONSTLENS_F3_LEN];
+	} testConstLens_t;
+
+	typedef struct testConstLensOther{
+		int64_t f1[testMaxItems];
+	} testConstLensOther_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
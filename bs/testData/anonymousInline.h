@@ -0,0 +1,29 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define PARENT_VALS_V_LEN 3
+	#define PARENT_VALS_LEN 2
+	typedef struct Parent{
+		struct{
+			int32_t Min;
+			int32_t Max;
+		} Limits;
+		struct{
+			int8_t V[PARENT_VALS_V_LEN];
+		} Vals[PARENT_VALS_LEN];
+	} Parent_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
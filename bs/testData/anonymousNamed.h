@@ -0,0 +1,33 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Parent_Limits{
+		int32_t Min;
+		int32_t Max;
+	} Parent_Limits_t;
+
+	#define PARENT_VALS_V_LEN 3
+	typedef struct Parent_Vals{
+		int8_t V[PARENT_VALS_V_LEN];
+	} Parent_Vals_t;
+
+	#define PARENT_VALS_LEN 2
+	typedef struct Parent{
+		Parent_Limits_t Limits;
+		Parent_Vals_t Vals[PARENT_VALS_LEN];
+	} Parent_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,26 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define S2_F7_LEN 5
+	#define S2_GRID_LEN 2
+	#define S2_GRID_LEN_1 3
+	typedef struct s2{
+		uint32_t f7[S2_F7_LEN];
+		int32_t grid[S2_GRID_LEN][S2_GRID_LEN_1];
+		int8_t f9;
+	} s2_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,30 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define testMaxItems 4
+	#define TESTCONSTLENS_F2_LEN_1 2
+	#define TESTCONSTLENS_F3_LEN 3
+	typedef struct testConstLens{
+		int32_t f1[testMaxItems];
+		uint8_t f2[testMaxItems][TESTCONSTLENS_F2_LEN_1];
+		int16_t f3[TESTCONSTLENS_F3_LEN];
+	} testConstLens_t;
+
+	typedef struct testConstLensOther{
+		int64_t f1[testMaxItems];
+	} testConstLensOther_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,26 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t f1;
+		uint8_t _reserved_1[3];
+		int32_t f2;
+		uint32_t _reserved_8[1];
+		int64_t f3;
+		uint8_t _reserved_24[8];
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,24 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <complex.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef float _Complex signal_t;
+
+	typedef struct s1{
+		signal_t f1;
+		double _Complex f2;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
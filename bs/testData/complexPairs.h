@@ -0,0 +1,33 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct complex64{
+		float re;
+		float im;
+	} complex64_t;
+
+	typedef struct complex128{
+		double re;
+		double im;
+	} complex128_t;
+
+	typedef complex64_t signal_t;
+
+	typedef struct s1{
+		signal_t f1;
+		complex128_t f2;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,86 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define MAX INT32_C(5)
+
+	typedef int8_t testColor_t;
+	enum testColor{
+		testColor_Red = 0,
+		testColor_Green = 1,
+		testColor_Blue = 2,
+	};
+
+	typedef struct Slice_int16{
+		int16_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_int16_t;
+
+	typedef struct Slice_testColor{
+		testColor_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_testColor_t;
+
+	typedef struct testConcurrentInner{
+		int32_t f1;
+		testColor_t f2;
+	} testConcurrentInner_t;
+
+	typedef struct Slice_testConcurrentInner{
+		testConcurrentInner_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_testConcurrentInner_t;
+
+	typedef struct testConcurrentA_f3{
+		int64_t f1;
+	} testConcurrentA_f3_t;
+
+	typedef struct testConcurrentA{
+		testConcurrentInner_t f1;
+		Slice_testConcurrentInner_t f2;
+		testConcurrentA_f3_t f3;
+	} testConcurrentA_t;
+
+	typedef struct testConcurrentB{
+		testConcurrentInner_t* f1;
+		Slice_int16_t f2;
+		testColor_t f3;
+	} testConcurrentB_t;
+
+	typedef struct testConcurrentC{
+		testConcurrentInner_t f1[2];
+		Slice_testColor_t f2;
+	} testConcurrentC_t;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+	#define SLICE_INT16_LAYOUT_HASH 0x482887d2c0f2c9e1ULL
+	#define SLICE_TESTCOLOR_LAYOUT_HASH 0x890fb5986f0952d1ULL
+	#define SLICE_TESTCONCURRENTINNER_LAYOUT_HASH 0x1a7501b095348f71ULL
+	#define TESTCONCURRENTA_LAYOUT_HASH 0x8f903b4139ccb3dfULL
+	#define TESTCONCURRENTA_F3_LAYOUT_HASH 0x2b3db426653c9fa4ULL
+	#define TESTCONCURRENTB_LAYOUT_HASH 0x5cf176e6e97dc764ULL
+	#define TESTCONCURRENTC_LAYOUT_HASH 0x1725bf714a10c880ULL
+	#define TESTCONCURRENTINNER_LAYOUT_HASH 0x271a4cc8c2b2d5e3ULL
+	#define HEADER_GUARD_LAYOUT_HASH 0x0e2a5425a3881b81ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
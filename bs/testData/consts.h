@@ -0,0 +1,28 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <math.h>
+#include <stdbool.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	static const bool ENABLED = true;
+	static const int32_t MAX_PEERS = INT32_C(16);
+	static const double_t SCALE = 0.5;
+	static const char* const VERSION = "1.2.3";
+
+	typedef struct s1{
+		int8_t f1;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,28 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stdint.h>
+#include <uuid/uuid.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		uuid_t f1;
+		int8_t f2;
+	} s1_t;
+
+	static_assert(sizeof(uuid_t) == 16, "uuid_t must have the same size as the Go type sbcgostructgen.testUUID");
+	static_assert(alignof(uuid_t) == 1, "uuid_t must have the same alignment as the Go type sbcgostructgen.testUUID");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,44 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	// A point in 2D space.
+	//
+	// Used to test that doc comments are carried into the header.
+	//
+	// Generated from the Go type github.com/barbell-math/smoothbrain-cgostructgen.testDocPoint
+	typedef struct testDocPoint{
+		// The version of the layout
+		uint8_t Version;
+		// The horizontal position
+		int32_t X;
+		// The vertical position
+		int32_t Y;
+		// A comment that spans
+		// multiple lines and ends a * / block
+		int32_t Z;
+		int32_t Untagged;
+	} testDocPoint_t;
+
+	// Points that are connected in order.
+	//
+	// Generated from the Go type github.com/barbell-math/smoothbrain-cgostructgen.testDocPath
+	typedef struct testDocPath{
+		// The first point
+		testDocPoint_t Start;
+		testDocPoint_t* Ptr;
+	} testDocPath_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,21 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct testDocPath{
+		int32_t Other;
+	} testDocPath_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,27 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define MSG_FLAGS_LEN 2
+	typedef struct Msg{
+		struct{
+			uint8_t Version;
+			uint8_t Flags[MSG_FLAGS_LEN];
+		};
+		int32_t ID;
+		uint16_t Len;
+	} Msg_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,36 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef int8_t testColor_t;
+	enum testColor{
+		testColor_Red = 0,
+		testColor_Green = 1,
+		testColor_Blue = 2,
+	};
+	static inline const char* testColor_name(testColor_t v) {
+		switch (v) {
+			case testColor_Red: return "Red";
+			case testColor_Green: return "Green";
+			case testColor_Blue: return "Blue";
+			default: return "testColor(unknown)";
+		}
+	}
+
+	typedef struct s1{
+		testColor_t f1;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
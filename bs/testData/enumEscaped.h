@@ -0,0 +1,30 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef uint8_t testEscaped_t;
+	enum testEscaped{
+		testEscaped_na_ve_x_ = 0,
+		testEscaped_a_b_ = 1,
+	};
+	static inline const char* testEscaped_name(testEscaped_t v) {
+		switch (v) {
+			case testEscaped_na_ve_x_: return "na\303\257ve\"x\"";
+			case testEscaped_a_b_: return "a\011b\\";
+			default: return "testEscaped(unknown)";
+		}
+	}
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
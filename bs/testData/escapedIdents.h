@@ -0,0 +1,30 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <math.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef int32_t template_t;
+
+	typedef struct escaped{
+		template_t int_;
+		int32_t new_;
+		double_t class_;
+		uint8_t register_;
+		uint16_t gr_u00f6_u00dfe;
+		int16_t a_b;
+		int64_t x_Foo;
+	} escaped_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,35 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#ifndef GO_CGO_PROLOGUE_H
+	typedef long long GoInt;
+	typedef unsigned long long GoUint;
+#endif
+
+	typedef GoUint count_t;
+
+	typedef struct s1{
+		GoInt f1;
+		count_t f2;
+	} s1_t;
+
+	static_assert(sizeof(GoInt) == 8, "GoInt must have the same size as the Go type int");
+	static_assert(alignof(GoInt) == 8, "GoInt must have the same alignment as the Go type int");
+	static_assert(sizeof(GoUint) == 8, "GoUint must have the same size as the Go type uint");
+	static_assert(alignof(GoUint) == 8, "GoUint must have the same alignment as the Go type uint");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
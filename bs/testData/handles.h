@@ -0,0 +1,25 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef uintptr_t ErrorHandle;
+	typedef uintptr_t TestCallbacksHandle;
+
+	typedef struct s1{
+		TestCallbacksHandle f1;
+		ErrorHandle f2;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
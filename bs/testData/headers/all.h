@@ -0,0 +1,12 @@
+#ifndef ALL_H
+#define ALL_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "color.h"
+#include "common.h"
+#include "image.h"
+#include "smoothbrain-cgostructgen.h"
+
+#endif
@@ -0,0 +1,33 @@
+#ifndef COLOR_H
+#define COLOR_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "common.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct RGBA{
+		uint8_t R;
+		uint8_t G;
+		uint8_t B;
+		uint8_t A;
+	} RGBA_t;
+
+	typedef struct Slice_RGBA{
+		RGBA_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_RGBA_t;
+
+	#define RGBA_LAYOUT_HASH 0x27a8b3ff20e16757ULL
+	#define SLICE_RGBA_LAYOUT_HASH 0xde938dc6000755f9ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
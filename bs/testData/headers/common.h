@@ -0,0 +1,32 @@
+#ifndef COMMON_H
+#define COMMON_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Slice_int32{
+		int32_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_int32_t;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+	#define SLICE_INT32_LAYOUT_HASH 0x4284b9d702daa4a5ULL
+	#define COMMON_H_LAYOUT_HASH 0xd936f6fda7bbe115ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,30 @@
+#ifndef IMAGE_H
+#define IMAGE_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "common.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Point{
+		ptrdiff_t X;
+		ptrdiff_t Y;
+	} Point_t;
+
+	typedef struct Rectangle{
+		Point_t Min;
+		Point_t Max;
+	} Rectangle_t;
+
+	#define POINT_LAYOUT_HASH 0x66c3fc2c5c151be9ULL
+	#define RECTANGLE_LAYOUT_HASH 0x0877c8079d86cbbcULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
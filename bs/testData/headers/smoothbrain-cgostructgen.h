@@ -0,0 +1,34 @@
+#ifndef SMOOTHBRAIN_CGOSTRUCTGEN_H
+#define SMOOTHBRAIN_CGOSTRUCTGEN_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "color.h"
+#include "common.h"
+#include "image.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct testHeaderShape_Meta{
+		int8_t Kind;
+	} testHeaderShape_Meta_t;
+
+	typedef struct testHeaderShape{
+		Rectangle_t Bounds;
+		RGBA_t Fill;
+		Slice_RGBA_t Palette;
+		Slice_int32_t Points;
+		testHeaderShape_Meta_t Meta;
+	} testHeaderShape_t;
+
+	#define TESTHEADERSHAPE_LAYOUT_HASH 0x5018cb1440b2bc15ULL
+	#define TESTHEADERSHAPE_META_LAYOUT_HASH 0xc6f3fa6067fb5573ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,31 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	static const double SCALE = 0.5;
+
+	typedef double meters_t;
+
+	typedef signed char testColor_t;
+	enum testColor{
+		testColor_Red = 0,
+	};
+
+	typedef struct s1{
+		meters_t f1;
+		float f2;
+		testColor_t f3;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,24 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t f1;
+	} s1_t;
+
+	#define S1_LAYOUT_HASH 0x4dca1e39a831522aULL
+	#define HEADER_GUARD_LAYOUT_HASH 0xd20f7da0c22c1400ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
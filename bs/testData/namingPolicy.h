@@ -0,0 +1,47 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef int8_t myproj_test_color;
+	enum{
+		myproj_test_color_Red = 0,
+		myproj_test_color_Green = 1,
+	};
+
+	#define MYPROJ_INNER_VAL_SOME_VALUES_LEN 2
+	typedef struct myproj_inner_val{
+		int32_t some_values[MYPROJ_INNER_VAL_SOME_VALUES_LEN];
+	} myproj_inner_val;
+
+	typedef struct myproj_slice_uint8{
+		uint8_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} myproj_slice_uint8;
+
+	typedef struct myproj_outer_val{
+		myproj_inner_val first_val;
+		myproj_inner_val* second_val;
+		myproj_test_color color;
+		myproj_slice_uint8 count;
+	} myproj_outer_val;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,27 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Config Config_t;
+	typedef struct Engine Engine_t;
+
+	typedef struct Session{
+		Engine_t* Engine;
+		Config_t* Config;
+		Engine_t* Workers[4];
+		uint64_t ID;
+	} Session_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
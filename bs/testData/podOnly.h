@@ -0,0 +1,22 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+// All structs are position-independent plain data, they contain no pointers
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t f1;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
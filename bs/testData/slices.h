@@ -0,0 +1,46 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <math.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Point{
+		float_t x;
+		float_t y;
+	} Point_t;
+
+	typedef struct Slice_Point{
+		Point_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_Point_t;
+
+	typedef struct Slice_uint64{
+		uint64_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_uint64_t;
+
+	typedef struct Batch{
+		Slice_Point_t points;
+		Slice_uint64_t ids;
+	} Batch_t;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
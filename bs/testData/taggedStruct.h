@@ -0,0 +1,29 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t first;
+		uint64_t _reserved_f2[2];
+		uint16_t _reserved_f3[1];
+		unsigned int f4;
+	} s1_t;
+
+	static_assert(sizeof(unsigned int) == 4, "unsigned int must have the same size as the Go type uint32");
+	static_assert(alignof(unsigned int) == 4, "unsigned int must have the same alignment as the Go type uint32");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,32 @@
+#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <math.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef double_t meters_t;
+	typedef uint64_t user_id_t;
+
+	typedef int8_t color_t;
+	enum color{
+		color_Red = 0,
+	};
+
+	typedef struct s1{
+		meters_t f1;
+		user_id_t f2;
+		color_t f3;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,145 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	complexCType struct {
+		// The C99 complex type that has the same layout as the Go type
+		name string
+		// The name of the pair struct that is used in place of the C99 complex
+		// type when [Opts.ComplexPairStructs] is true
+		pair string
+		// The type of the real and imaginary parts of the pair struct
+		part string
+		// The builtin Go type the pair struct represents
+		goType reflect.Type
+	}
+)
+
+var (
+	complexCTypes = map[reflect.Kind]complexCType{
+		reflect.Complex64: {
+			name:   "float _Complex",
+			pair:   "complex64",
+			part:   "float",
+			goType: reflect.TypeFor[complex64](),
+		},
+		reflect.Complex128: {
+			name:   "double _Complex",
+			pair:   "complex128",
+			part:   "double",
+			goType: reflect.TypeFor[complex128](),
+		},
+	}
+)
+
+// Returns an error if the supplied identifier is one of the macros that
+// <complex.h> defines and that header is written. The `complex` and
+// `imaginary` macros are always reserved (see [checkIdent]), but `I` is only
+// reserved when it is needed since it is a common name for Go fields.
+func (c *CGoStructGen) checkComplexIdent(name string) error {
+	if _, ok := c.includes["<complex.h>"]; !ok || name != "I" {
+		return nil
+	}
+	return sberr.Wrap(
+		InvalidIdentifierErr,
+		"'%s' is a macro defined by <complex.h>, which is included for complex types",
+		name,
+	)
+}
+
+// Checks that none of the identifiers that were added so far are macros
+// defined by <complex.h>, see [CGoStructGen.checkComplexIdent]. This is checked
+// once all types are generated because any type can cause the header to be
+// included, regardless of the order the identifiers were added in.
+func (c *CGoStructGen) checkComplexIdents() error {
+	if _, ok := c.includes["<complex.h>"]; !ok {
+		return nil
+	}
+	var checkFields func(structName string, fields []structField) error
+	checkFields = func(structName string, fields []structField) error {
+		for _, iterField := range fields {
+			if err := c.checkComplexIdent(iterField.name); err != nil {
+				return sberr.Wrap(err, "struct %s", structName)
+			}
+			if err := checkFields(structName, iterField.inline); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, structName := range slices.Sorted(maps.Keys(c.structs)) {
+		if err := checkFields(structName, c.structs[structName]); err != nil {
+			return err
+		}
+	}
+	for _, names := range []iter.Seq[string]{
+		maps.Keys(c.typeNames), maps.Keys(c.consts), maps.Keys(c.macros),
+	} {
+		for _, name := range slices.Sorted(names) {
+			if err := c.checkComplexIdent(name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, enum := range c.enums {
+		for _, v := range enum.values {
+			if err := c.checkComplexIdent(v.name); err != nil {
+				return sberr.Wrap(err, "enum %s", enum.name)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the C type and include for the supplied complex kind. False is
+// returned if the kind is not a complex kind.
+func (c *CGoStructGen) complexCType(kind reflect.Kind) (string, include, bool) {
+	cType, ok := complexCTypes[kind]
+	if !ok {
+		return "", "", false
+	}
+	if c.opts.ComplexPairStructs {
+		return c.cTypedef(c.typeCase(cType.pair)), "", true
+	}
+	return cType.name, "<complex.h>", true
+}
+
+// Registers the pair struct of the supplied complex kind so that it is written
+// to the header.
+func (c *CGoStructGen) checkComplexPair(kind reflect.Kind) error {
+	cType := complexCTypes[kind]
+	name := c.typeCase(cType.pair)
+	if err := c.claimTypeNames(
+		cType.goType, c.cTag(name), c.cTypedef(name),
+	); err != nil {
+		return err
+	}
+	c.complexPairs[kind] = struct{}{}
+	return nil
+}
+
+func (c *CGoStructGen) templateComplexPairs(f *os.File) {
+	kinds := []reflect.Kind{}
+	for kind := range c.complexPairs {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		cType := complexCTypes[kind]
+		name := c.typeCase(cType.pair)
+		fmt.Fprintf(f, "\ttypedef struct %s{\n", c.cTag(name))
+		fmt.Fprintf(f, "\t\t%s re;\n", cType.part)
+		fmt.Fprintf(f, "\t\t%s im;\n", cType.part)
+		fmt.Fprintf(f, "\t} %s;\n\n", c.cTypedef(name))
+	}
+}
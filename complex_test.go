@@ -0,0 +1,165 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForComplex(t *testing.T) {
+	type s1 struct {
+		f1 complex64
+		f2 [2]complex128
+		f3 *complex64
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "float _Complex f1", fields[0].String())
+	sbtest.Eq(t, "double _Complex f2[2]", fields[1].String())
+	sbtest.Eq(t, "float _Complex* f3", fields[2].String())
+	sbtest.MapsMatch(t, map[include]struct{}{"<complex.h>": {}}, res.includes)
+	sbtest.Eq(t, 0, len(res.complexPairs))
+}
+
+func TestGenerateForComplexPairStructs(t *testing.T) {
+	type s1 struct {
+		f1 complex64
+		f2 [2]complex128
+	}
+	res := New(Opts{ComplexPairStructs: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "complex64_t f1", fields[0].String())
+	sbtest.Eq(t, "complex128_t f2[2]", fields[1].String())
+	sbtest.Eq(t, 0, len(res.includes))
+	sbtest.MapsMatch(t,
+		map[reflect.Kind]struct{}{reflect.Complex64: {}, reflect.Complex128: {}},
+		res.complexPairs,
+	)
+}
+
+func TestGenerateForComplexPODOnly(t *testing.T) {
+	type s1 struct{ f1 complex128 }
+	res := New(Opts{PODOnly: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+}
+
+func TestGenerateForComplexMacroNames(t *testing.T) {
+	type s1 struct{ I int32 }
+	type s2 struct{ f1 complex64 }
+
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s2](res))
+	sbtest.MapsMatch(t, map[include]struct{}{"<stdint.h>": {}}, res.includes)
+
+	res = New(Opts{})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s1](res))
+	sbtest.ContainsError(
+		t, InvalidIdentifierErr, GenerateConst(res, "I", int32(1)),
+	)
+
+	// Pair structs do not include <complex.h>. The constant is static so that
+	// it does not replace the field name.
+	res = New(Opts{ComplexPairStructs: true, StaticConsts: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.Nil(t, GenerateConst(res, "I", int32(1)))
+}
+
+func TestWriteComplex(t *testing.T) {
+	type signal complex64
+	type s1 struct {
+		f1 signal
+		f2 complex128
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/complex.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/complex.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <complex.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef float _Complex signal_t;
+
+	typedef struct s1{
+		signal_t f1;
+		double _Complex f2;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestWriteComplexPairStructs(t *testing.T) {
+	type signal complex64
+	type s1 struct {
+		f1 signal
+		f2 complex128
+	}
+	res := New(Opts{ComplexPairStructs: true})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/complexPairs.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/complexPairs.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct complex64{
+		float re;
+		float im;
+	} complex64_t;
+
+	typedef struct complex128{
+		double re;
+		double im;
+	} complex128_t;
+
+	typedef complex64_t signal_t;
+
+	typedef struct s1{
+		signal_t f1;
+		complex128_t f2;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
@@ -0,0 +1,304 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	cConst struct {
+		kind reflect.Kind
+		val  string
+	}
+)
+
+var (
+	constMacros = map[reflect.Kind]string{
+		reflect.Int8:   "INT8_C",
+		reflect.Int16:  "INT16_C",
+		reflect.Int32:  "INT32_C",
+		reflect.Int64:  "INT64_C",
+		reflect.Uint8:  "UINT8_C",
+		reflect.Uint16: "UINT16_C",
+		reflect.Uint32: "UINT32_C",
+		reflect.Uint64: "UINT64_C",
+	}
+)
+
+// Adds a constant to the struct generator that will be written to the header
+// as either a `#define` or a `static const` value, depending on
+// [Opts.StaticConsts]. The C type of the constant is determined by the Go type
+// of the supplied value, so the value should be typed (i.e. `int32(5)` rather
+// than `5`). The following value types are allowed:
+//   - int8, int16, int32, int64
+//   - uint8, uint16, uint32, uint64
+//   - float32, float64
+//   - string
+//   - bool
+//   - named types whose underlying type is one of the above
+//
+// Integers are written with the matching stdint macro, i.e. `INT64_C(5)`, so
+// they have the correct type in C. Constants are written sorted by name.
+// Adding a constant with the same name and value multiple times is allowed,
+// adding a constant with the same name and a different value is an error, as is
+// adding a constant with the name of an array length macro (see
+// [Opts.EmitArrayLenMacros]), a C type, or an enum value. Constants written as
+// a `#define` also cannot have the name of a struct field, because the macro
+// would replace the field name. Types added after the constant are checked the
+// same way. It is safe to call this function from multiple goroutines.
+func GenerateConst(c *CGoStructGen, name string, val any) error {
+	var err error
+	var cc cConst
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = c.cIdent(name)
+	if !cIdentRegex.MatchString(name) {
+		err = sberr.Wrap(
+			InvalidTypeErr, "'%s' is not a valid C identifier", name,
+		)
+		goto errExit
+	}
+	if err = checkIdent(name); err != nil {
+		err = sberr.Wrap(err, "constant %s", name)
+		goto errExit
+	}
+	if err = c.checkComplexIdent(name); err != nil {
+		err = sberr.Wrap(err, "constant %s", name)
+		goto errExit
+	}
+	if cc, err = newCConst(reflect.ValueOf(val)); err != nil {
+		err = sberr.Wrap(err, "constant %s", name)
+		goto errExit
+	}
+	if owner, ok := c.macros[name]; ok {
+		err = sberr.Wrap(
+			DuplicateNameErr,
+			"The constant %s has the same name as the array length macro of %s",
+			name, owner,
+		)
+		goto errExit
+	}
+	if err = c.checkConstName(name); err != nil {
+		goto errExit
+	}
+	if other, ok := c.consts[name]; ok && other != cc {
+		err = sberr.Wrap(
+			DuplicateNameErr,
+			"The constant %s was already added with a different value",
+			name,
+		)
+		goto errExit
+	}
+
+	c.consts[name] = cc
+	if _, i, _ := c.kindCType(cc.kind); i != "" {
+		c.includes[i] = struct{}{}
+	}
+
+errExit:
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+// Checks that the constant with the supplied name does not have the name of a
+// C type, layout hash macro, enum value, or, if it is written as a `#define`,
+// struct field.
+func (c *CGoStructGen) checkConstName(name string) error {
+	if other, ok := c.typeNames[name]; ok {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The constant %s has the same name as the C type of %s",
+			name, other,
+		)
+	}
+	if owner, ok := c.hashMacros[name]; ok {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The constant %s has the same name as the layout hash macro of %s",
+			name, owner,
+		)
+	}
+	for _, enum := range c.enums {
+		for _, v := range enum.values {
+			if v.name == name {
+				return sberr.Wrap(
+					DuplicateNameErr,
+					"The constant %s has the same name as a value of enum %s",
+					name, enum.name,
+				)
+			}
+		}
+	}
+	if c.opts.StaticConsts {
+		return nil
+	}
+
+	var hasField func(fields []structField) bool
+	hasField = func(fields []structField) bool {
+		return slices.ContainsFunc(fields, func(f structField) bool {
+			return f.name == name || hasField(f.inline)
+		})
+	}
+	for _, structName := range slices.Sorted(maps.Keys(c.structs)) {
+		if hasField(c.structs[structName]) {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The constant %s has the same name as a field of struct %s",
+				name, structName,
+			)
+		}
+	}
+	if len(c.complexPairs) > 0 && (name == "re" || name == "im") {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The constant %s has the same name as a field of the complex pair structs",
+			name,
+		)
+	}
+	return nil
+}
+
+// Checks the names of all the constants that were added so far, see
+// [CGoStructGen.checkConstName]. This is checked whenever types are added
+// because a constant can be added before the type it shares a name with.
+func (c *CGoStructGen) checkConstNames() error {
+	for _, name := range slices.Sorted(maps.Keys(c.consts)) {
+		if err := c.checkConstName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adds all of the supplied constants to the struct generator. The keys of the
+// map are the C names of the constants. See [GenerateConst] for details.
+func GenerateConsts(c *CGoStructGen, consts map[string]any) error {
+	names := slices.Collect(maps.Keys(consts))
+	slices.Sort(names)
+	for _, name := range names {
+		if err := GenerateConst(c, name, consts[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newCConst(refVal reflect.Value) (cConst, error) {
+	if !refVal.IsValid() {
+		return cConst{}, sberr.Wrap(InvalidTypeErr, "Got nil value")
+	}
+
+	kind := refVal.Kind()
+	switch kind {
+	case reflect.Int, reflect.Uint:
+		return cConst{}, sberr.Wrap(
+			UnderspecifiedTypeErr,
+			"A %s can be varying sizes in C, specify bit size to fix (i.e. int32(5) instead of 5)",
+			kind,
+		)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v := refVal.Int()
+		bits := refVal.Type().Bits()
+		if v == math.MinInt64>>(64-bits) {
+			// The negation of the minimum value does not fit in the type, so
+			// it cannot be written as a literal
+			return cConst{
+				kind: kind,
+				val: fmt.Sprintf(
+					"(-%s(%d) - 1)", constMacros[kind], -(v + 1),
+				),
+			}, nil
+		}
+		return cConst{
+			kind: kind,
+			val:  fmt.Sprintf("%s(%d)", constMacros[kind], v),
+		}, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cConst{
+			kind: kind,
+			val:  fmt.Sprintf("%s(%d)", constMacros[kind], refVal.Uint()),
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		v := refVal.Float()
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return cConst{}, sberr.Wrap(
+				InvalidTypeErr, "Cannot write %v as a C constant", v,
+			)
+		}
+		bits := refVal.Type().Bits()
+		str := strconv.FormatFloat(v, 'g', -1, bits)
+		if !strings.ContainsAny(str, ".eE") {
+			str += ".0"
+		}
+		if kind == reflect.Float32 {
+			str += "f"
+		}
+		return cConst{kind: kind, val: str}, nil
+	case reflect.Bool:
+		return cConst{kind: kind, val: strconv.FormatBool(refVal.Bool())}, nil
+	case reflect.String:
+		return cConst{kind: kind, val: cStringLiteral(refVal.String())}, nil
+	default:
+		return cConst{}, sberr.Wrap(
+			InvalidTypeErr, "Cannot write a Go %s as a C constant", kind,
+		)
+	}
+}
+
+// Returns the supplied string as a C string literal. Any bytes that are not
+// printable ASCII characters are written as octal escapes.
+func cStringLiteral(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := range len(s) {
+		b := s[i]
+		switch {
+		case b == '"' || b == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case b < 0x20 || b >= 0x7f:
+			fmt.Fprintf(&sb, "\\%03o", b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func (c *CGoStructGen) templateConsts(f *os.File) {
+	names := slices.Collect(maps.Keys(c.consts))
+	if len(names) == 0 {
+		return
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		cc := c.consts[name]
+		if !c.opts.StaticConsts {
+			fmt.Fprintf(f, "\t#define %s %s\n", name, cc.val)
+		} else if cc.kind == reflect.String {
+			fmt.Fprintf(
+				f, "\tstatic const char* const %s = %s;\n", name, cc.val,
+			)
+		} else {
+			cType, _, _ := c.kindCType(cc.kind)
+			fmt.Fprintf(
+				f, "\tstatic const %s %s = %s;\n", cType, name, cc.val,
+			)
+		}
+	}
+	f.WriteString("\n")
+}
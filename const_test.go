@@ -0,0 +1,190 @@
+package sbcgostructgen
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateConst(t *testing.T) {
+	type meters float64
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateConst(res, "A", int8(-5)))
+	sbtest.Nil(t, GenerateConst(res, "B", uint16(5)))
+	sbtest.Nil(t, GenerateConst(res, "C", int64(math.MinInt64)))
+	sbtest.Nil(t, GenerateConst(res, "D", int32(math.MinInt32)))
+	sbtest.Nil(t, GenerateConst(res, "E", uint64(math.MaxUint64)))
+	sbtest.Nil(t, GenerateConst(res, "F", float32(1.5)))
+	sbtest.Nil(t, GenerateConst(res, "G", float64(2)))
+	sbtest.Nil(t, GenerateConst(res, "H", meters(1e100)))
+	sbtest.Nil(t, GenerateConst(res, "I", true))
+	sbtest.Nil(t, GenerateConst(res, "J", "a\"b\\c\n\x7f"))
+
+	sbtest.MapsMatch(t,
+		map[string]cConst{
+			"A": {kind: reflect.Int8, val: "INT8_C(-5)"},
+			"B": {kind: reflect.Uint16, val: "UINT16_C(5)"},
+			"C": {kind: reflect.Int64, val: "(-INT64_C(9223372036854775807) - 1)"},
+			"D": {kind: reflect.Int32, val: "(-INT32_C(2147483647) - 1)"},
+			"E": {kind: reflect.Uint64, val: "UINT64_C(18446744073709551615)"},
+			"F": {kind: reflect.Float32, val: "1.5f"},
+			"G": {kind: reflect.Float64, val: "2.0"},
+			"H": {kind: reflect.Float64, val: "1e+100"},
+			"I": {kind: reflect.Bool, val: "true"},
+			"J": {kind: reflect.String, val: `"a\"b\\c\012\177"`},
+		},
+		res.consts,
+	)
+}
+
+func TestGenerateConstInvalid(t *testing.T) {
+	res := New(Opts{})
+	sbtest.ContainsError(t, UnderspecifiedTypeErr, GenerateConst(res, "A", 5))
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateConst(res, "A", nil))
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateConst(res, "A", []int8{}))
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateConst(res, "A", math.NaN()))
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateConst(res, "1A", int8(1)))
+	sbtest.Eq(t, 0, len(res.consts))
+}
+
+func TestGenerateConstDuplicate(t *testing.T) {
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateConst(res, "A", int8(1)))
+	sbtest.Nil(t, GenerateConst(res, "A", int8(1)))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateConst(res, "A", int8(2)))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateConst(res, "A", int16(1)))
+}
+
+func TestGenerateConstArrayLenMacro(t *testing.T) {
+	type s1 struct{ f1 [2]int32 }
+	res := New(Opts{EmitArrayLenMacros: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.ContainsError(
+		t, DuplicateNameErr, GenerateConst(res, "S1_F1_LEN", int32(2)),
+	)
+	sbtest.Eq(t, 0, len(res.consts))
+	sbtest.Nil(t, GenerateConst(res, "S1_F2_LEN", int32(2)))
+}
+
+func TestGenerateConstTypeNames(t *testing.T) {
+	type peers struct {
+		MaxPeers int32
+		Handle   ErrorHandle
+	}
+	res := New(Opts{HandleFields: true})
+	sbtest.Nil(t, GenerateFor[peers](res))
+	sbtest.Nil(t, GenerateEnum(res, testColorRed))
+	for _, name := range []string{
+		"peers", "peers_t", "ErrorHandle", "testColor_Red", "MaxPeers",
+	} {
+		sbtest.ContainsError(
+			t, DuplicateNameErr, GenerateConst(res, name, int32(1)),
+		)
+	}
+	sbtest.Eq(t, 0, len(res.consts))
+
+	// Static constants do not replace field names
+	res = New(Opts{StaticConsts: true})
+	sbtest.Nil(t, GenerateFor[peers](res))
+	sbtest.Nil(t, GenerateConst(res, "MaxPeers", int32(1)))
+	sbtest.ContainsError(
+		t, DuplicateNameErr, GenerateConst(res, "peers_t", int32(1)),
+	)
+}
+
+func TestGenerateConstBeforeTypes(t *testing.T) {
+	type peers struct{ MaxPeers int32 }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateConst(res, "MaxPeers", int32(1)))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[peers](res))
+	sbtest.Eq(t, 0, len(res.structs))
+
+	res = New(Opts{HandleFields: true})
+	sbtest.Nil(t, GenerateConst(res, "peers_t", int32(1)))
+	sbtest.Nil(t, GenerateConst(res, "testColor_Green", int32(1)))
+	sbtest.Nil(t, GenerateConst(res, "ErrorHandle", int32(1)))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[peers](res))
+	sbtest.ContainsError(
+		t, DuplicateNameErr, GenerateEnum(res, testColorGreen),
+	)
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateHandle[error](res))
+	sbtest.Eq(t, 0, len(res.structs))
+	sbtest.Eq(t, 0, len(res.enums))
+	sbtest.Eq(t, 0, len(res.handles))
+	sbtest.Eq(t, 0, len(res.typeNames))
+}
+
+func TestGenerateConsts(t *testing.T) {
+	res := New(Opts{})
+	err := GenerateConsts(res, map[string]any{
+		"MAX_PEERS": int32(16),
+		"VERSION":   "1.2.3",
+	})
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, 2, len(res.consts))
+
+	err = GenerateConsts(res, map[string]any{"BAD": 1})
+	sbtest.ContainsError(t, UnderspecifiedTypeErr, err)
+}
+
+func TestWriteConsts(t *testing.T) {
+	type s1 struct{ f1 int8 }
+	for _, static := range []bool{false, true} {
+		res := New(Opts{StaticConsts: static})
+		err := GenerateConsts(res, map[string]any{
+			"VERSION":   "1.2.3",
+			"MAX_PEERS": int32(16),
+			"SCALE":     float64(0.5),
+			"ENABLED":   true,
+		})
+		sbtest.Nil(t, err)
+		err = GenerateFor[s1](res)
+		sbtest.Nil(t, err)
+		err = res.WriteTo("./bs/testData/consts.h", "HEADER_GUARD")
+		sbtest.Nil(t, err)
+
+		data, err := os.ReadFile("./bs/testData/consts.h")
+		sbtest.Nil(t, err)
+		consts := `	#define ENABLED true
+	#define MAX_PEERS INT32_C(16)
+	#define SCALE 0.5
+	#define VERSION "1.2.3"
+`
+		if static {
+			consts = `	static const bool ENABLED = true;
+	static const int32_t MAX_PEERS = INT32_C(16);
+	static const double_t SCALE = 0.5;
+	static const char* const VERSION = "1.2.3";
+`
+		}
+		exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <math.h>
+#include <stdbool.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+` + consts + `
+	typedef struct s1{
+		int8_t f1;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+		sbtest.Eq(t, string(data), exp)
+	}
+}
@@ -0,0 +1,183 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// An interface that allows a Go type to describe its own C representation.
+	// Any struct field whose type implements this interface, either with a
+	// value or pointer receiver, will be written with the returned C type name
+	// and the returned includes will be added to the header. The includes must
+	// be surrounded with either angle brackets or quotes, i.e. `<uuid.h>` or
+	// `"fixed.h"`. The C type may end with array dimensions, i.e.
+	// `uint8_t[16]`, in which case the field is written as an array, i.e.
+	// `uint8_t id[16]`.
+	//
+	// The C type must have the same size and alignment as the Go type. This is
+	// verified with static asserts in the generated header.
+	CTyper interface {
+		CType() (name string, includes []string)
+	}
+
+	staticAssert struct {
+		cond string
+		msg  string
+	}
+)
+
+var (
+	cTyperType   = reflect.TypeFor[CTyper]()
+	includeRegex = regexp.MustCompile(`^(<[^<>"]+>|"[^<>"]+")$`)
+	cDimRegex    = regexp.MustCompile(`\[([1-9][0-9]*)\]$`)
+)
+
+// Returns the C type and includes for the supplied type if it implements
+// [CTyper]. Pointer types are never considered to be [CTyper]s so that a
+// pointer to a type that implements [CTyper] is written as a pointer to the C
+// type. Interface types are not [CTyper]s either, as there is no value to call
+// the method on.
+func cTyperFor(refType reflect.Type) (string, []string, bool) {
+	if refType.Kind() == reflect.Pointer || refType.Kind() == reflect.Interface {
+		return "", nil, false
+	}
+	if refType.Implements(cTyperType) {
+		name, includes := reflect.Zero(refType).Interface().(CTyper).CType()
+		return name, includes, true
+	}
+	if reflect.PointerTo(refType).Implements(cTyperType) {
+		name, includes := reflect.New(refType).Interface().(CTyper).CType()
+		return name, includes, true
+	}
+	return "", nil, false
+}
+
+// Checks the C type and includes of a type that is not generated by this
+// package, such as those returned by a [CTyper]. If verify is true the type is
+// registered so that its layout is verified with static asserts in the
+// generated header.
+func (c *CGoStructGen) checkExternalCType(
+	refType reflect.Type,
+	name string,
+	includes []string,
+	verify bool,
+) error {
+	if base, _ := splitCTypeDims(name); !cTypeRegex.MatchString(base) {
+		return sberr.Wrap(
+			InvalidTypeErr, "'%s' used for %s is not a valid C type",
+			name, refType,
+		)
+	}
+	for _, i := range includes {
+		if !includeRegex.MatchString(i) {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"'%s' used for %s is not a valid include, expected <file> or \"file\"",
+				i, refType,
+			)
+		}
+	}
+	if !verify {
+		return nil
+	}
+	if other, ok := c.cTypes[name]; ok && (other.Size() != refType.Size() ||
+		other.Align() != refType.Align()) {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The C type %s is used by both %s and %s which have different layouts",
+			name, other, refType,
+		)
+	}
+	c.cTypes[name] = refType
+	c.includes["<assert.h>"] = struct{}{}
+	c.includes["<stdalign.h>"] = struct{}{}
+	return nil
+}
+
+// Splits the array dimensions off of the end of the supplied C type, i.e.
+// `uint8_t[4][16]` is split into `uint8_t` and `[4, 16]`.
+func splitCTypeDims(name string) (string, []int) {
+	dims := []int{}
+	for {
+		m := cDimRegex.FindStringSubmatchIndex(name)
+		if m == nil {
+			return name, dims
+		}
+		dim, _ := strconv.Atoi(name[m[2]:m[3]])
+		dims = append([]int{dim}, dims...)
+		name = name[:m[0]]
+	}
+}
+
+// Returns the struct field for a field whose C type is not generated by this
+// package, such as those returned by a [CTyper]. Any array dimensions in the C
+// type are added after the dimensions of the Go arrays the type is held in.
+// Pointers to arrays are written as a pointer to the first element.
+func (c *CGoStructGen) externalField(
+	name string,
+	field reflect.StructField,
+	tMod typeModifier,
+) structField {
+	base, dims := splitCTypeDims(name)
+	if len(dims) > 0 && tMod.pntrs == 0 {
+		tMod.typeMod = TypeModArray
+		tMod.tModAmnts = append(slices.Clone(tMod.tModAmnts), dims...)
+	}
+	return structField{
+		_type:        base,
+		name:         c.cFieldName(field),
+		typeModifier: tMod,
+		offset:       field.Offset,
+		size:         field.Type.Size(),
+		align:        uintptr(field.Type.Align()),
+	}
+}
+
+// Returns the static asserts that verify that the layout of all C types that
+// were not generated by this package match the Go types they represent.
+func (c *CGoStructGen) staticAsserts() []staticAssert {
+	names := slices.Collect(maps.Keys(c.cTypes))
+	slices.Sort(names)
+
+	rv := []staticAssert{}
+	for _, name := range names {
+		refType := c.cTypes[name]
+		rv = append(
+			rv,
+			staticAssert{
+				cond: fmt.Sprintf("sizeof(%s) == %d", name, refType.Size()),
+				msg: fmt.Sprintf(
+					"%s must have the same size as the Go type %s",
+					name, refType,
+				),
+			},
+			staticAssert{
+				cond: fmt.Sprintf("alignof(%s) == %d", name, refType.Align()),
+				msg: fmt.Sprintf(
+					"%s must have the same alignment as the Go type %s",
+					name, refType,
+				),
+			},
+		)
+	}
+	return rv
+}
+
+func (c *CGoStructGen) templateStaticAsserts(f *os.File) {
+	asserts := c.staticAsserts()
+	if len(asserts) == 0 {
+		return
+	}
+	for _, a := range asserts {
+		fmt.Fprintf(f, "\tstatic_assert(%s, %s);\n", a.cond, cStringLiteral(a.msg))
+	}
+	f.WriteString("\n")
+}
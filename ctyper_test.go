@@ -0,0 +1,207 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	testUUID  [16]uint8
+	testFixed struct{ v int32 }
+	testBadC  int32
+	testBadI  int32
+	testRawID [16]uint8
+	testIface interface{ CTyper }
+)
+
+func (testRawID) CType() (string, []string) {
+	return "uint8_t[16]", []string{"<stdint.h>"}
+}
+
+func (testUUID) CType() (string, []string) {
+	return "uuid_t", []string{"<uuid/uuid.h>"}
+}
+
+func (*testFixed) CType() (string, []string) {
+	return "fixed_t", []string{`"fixed.h"`}
+}
+
+func (testBadC) CType() (string, []string) {
+	return "bad;", nil
+}
+
+func (testBadI) CType() (string, []string) {
+	return "int32_t", []string{"stdint.h"}
+}
+
+func TestCTyperFor(t *testing.T) {
+	name, includes, ok := cTyperFor(reflect.TypeFor[testUUID]())
+	sbtest.True(t, ok)
+	sbtest.Eq(t, "uuid_t", name)
+	sbtest.SlicesMatch(t, []string{"<uuid/uuid.h>"}, includes)
+
+	name, includes, ok = cTyperFor(reflect.TypeFor[testFixed]())
+	sbtest.True(t, ok)
+	sbtest.Eq(t, "fixed_t", name)
+	sbtest.SlicesMatch(t, []string{`"fixed.h"`}, includes)
+
+	_, _, ok = cTyperFor(reflect.TypeFor[*testFixed]())
+	sbtest.False(t, ok)
+	_, _, ok = cTyperFor(reflect.TypeFor[int32]())
+	sbtest.False(t, ok)
+	_, _, ok = cTyperFor(reflect.TypeFor[testIface]())
+	sbtest.False(t, ok)
+}
+
+func TestSplitCTypeDims(t *testing.T) {
+	name, dims := splitCTypeDims("uint8_t")
+	sbtest.Eq(t, "uint8_t", name)
+	sbtest.SlicesMatch(t, []int{}, dims)
+
+	name, dims = splitCTypeDims("uint8_t[16]")
+	sbtest.Eq(t, "uint8_t", name)
+	sbtest.SlicesMatch(t, []int{16}, dims)
+
+	name, dims = splitCTypeDims("unsigned char*[4][16]")
+	sbtest.Eq(t, "unsigned char*", name)
+	sbtest.SlicesMatch(t, []int{4, 16}, dims)
+
+	name, dims = splitCTypeDims("uint8_t[0]")
+	sbtest.Eq(t, "uint8_t[0]", name)
+	sbtest.SlicesMatch(t, []int{}, dims)
+}
+
+func TestGenerateForCTyperInterface(t *testing.T) {
+	type s1 struct{ f1 testIface }
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](New(Opts{})))
+}
+
+func TestGenerateForCTyperArray(t *testing.T) {
+	type s1 struct {
+		f1 testRawID
+		f2 [2]testRawID
+		f3 *testRawID
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	structFieldsMatch(t,
+		[]structField{
+			{
+				_type: "uint8_t", name: "f1",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{16}},
+				offset:       0, size: 16, align: 1,
+			},
+			{
+				_type: "uint8_t", name: "f2",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{2, 16}},
+				offset:       16, size: 32, align: 1,
+			},
+			{
+				_type: "uint8_t", name: "f3",
+				typeModifier: typeModifier{typeMod: TypeModPntr, pntrs: 1},
+				offset:       48, size: 8, align: 8,
+			},
+		},
+		res.structs["s1"],
+	)
+	sbtest.SlicesMatch(t,
+		[]staticAssert{
+			{
+				cond: "sizeof(uint8_t[16]) == 16",
+				msg:  "uint8_t[16] must have the same size as the Go type sbcgostructgen.testRawID",
+			},
+			{
+				cond: "alignof(uint8_t[16]) == 1",
+				msg:  "uint8_t[16] must have the same alignment as the Go type sbcgostructgen.testRawID",
+			},
+		},
+		res.staticAsserts(),
+	)
+}
+
+func TestGenerateForCTyper(t *testing.T) {
+	type s1 struct {
+		f1 testUUID
+		f2 [2]testFixed
+		f3 *testFixed
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	structFieldsMatch(t,
+		[]structField{
+			{_type: "uuid_t", name: "f1", offset: 0, size: 16, align: 1},
+			{
+				_type: "fixed_t", name: "f2",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{2}},
+				offset:       16, size: 8, align: 4,
+			},
+			{
+				_type: "fixed_t", name: "f3",
+				typeModifier: typeModifier{typeMod: TypeModPntr, pntrs: 1},
+				offset:       24, size: 8, align: 8,
+			},
+		},
+		res.structs["s1"],
+	)
+}
+
+func TestGenerateForCTyperRoot(t *testing.T) {
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[testFixed](New(Opts{})))
+}
+
+func TestGenerateForCTyperInvalid(t *testing.T) {
+	type s1 struct{ f1 testBadC }
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](New(Opts{})))
+
+	type s2 struct{ f1 testBadI }
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s2](New(Opts{})))
+}
+
+func TestWriteCTyper(t *testing.T) {
+	type s1 struct {
+		f1 testUUID
+		f2 int8
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/ctyper.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/ctyper.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stdint.h>
+#include <uuid/uuid.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		uuid_t f1;
+		int8_t f2;
+	} s1_t;
+
+	static_assert(sizeof(uuid_t) == 16, "uuid_t must have the same size as the Go type sbcgostructgen.testUUID");
+	static_assert(alignof(uuid_t) == 1, "uuid_t must have the same alignment as the Go type sbcgostructgen.testUUID");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
@@ -0,0 +1,380 @@
+package sbcgostructgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// Describes how Go doc comments are written to the header. See
+	// [Opts.DocComments].
+	DocCommentStyle int
+
+	// The doc comments of a Go struct type and its fields, along with the
+	// names of the constants that give the lengths of its array fields.
+	typeDoc struct {
+		// True if the struct was found in the source of its package
+		found  bool
+		doc    string
+		fields map[string]string
+		// The names of the fields in the order they are declared, used to
+		// check that the source belongs to the reflected type
+		fieldNames []string
+		// Maps field names to the constant used for each array dimension,
+		// with an empty name for dimensions that are not a single constant
+		arrayLens map[string][]arrayLenConst
+	}
+
+	// A Go constant used as the length of an array dimension.
+	arrayLenConst struct {
+		name string
+		val  int64
+	}
+)
+
+const (
+	// Doc comments are not written to the header.
+	DocCommentsNone DocCommentStyle = iota
+	// Doc comments are written as `//` comments above the struct or field
+	// they belong to.
+	DocCommentsPlain
+	// Doc comments are written in the Doxygen style, i.e. `/** ... */` above
+	// structs and multi-line field comments and `///<` after single line field
+	// comments.
+	DocCommentsDoxygen
+)
+
+var (
+	DocCommentErr = errors.New("Could not load doc comments")
+)
+
+// Loads the doc comments and array length constants of the supplied struct
+// type from the source of its package, if either are needed. Types that are
+// not named, or that are not declared at the package level, do not have any.
+func (c *CGoStructGen) checkDocs(refType reflect.Type) error {
+	needed := c.opts.DocComments != DocCommentsNone ||
+		(c.opts.EmitArrayLenMacros && c.opts.ArrayLenConstNames)
+	if !needed || refType.Name() == "" || refType.PkgPath() == "" {
+		return nil
+	}
+	if _, ok := c.docs[refType]; ok {
+		return nil
+	}
+
+	pkgDocs, ok := c.pkgDocs[refType.PkgPath()]
+	if !ok {
+		var err error
+		if pkgDocs, err = loadPkgDocs(refType.PkgPath()); err != nil {
+			return sberr.Wrap(
+				DocCommentErr, "package %s: %s", refType.PkgPath(), err,
+			)
+		}
+		c.pkgDocs[refType.PkgPath()] = pkgDocs
+	}
+	doc := pkgDocs[refType.Name()]
+	if !doc.matches(refType) {
+		// A type declared inside a function can have the same name as one
+		// declared at the package level
+		doc = typeDoc{}
+	}
+	c.docs[refType] = doc
+	return nil
+}
+
+// Returns true if the supplied type has the same fields, in the same order, as
+// the struct the doc comments were parsed from.
+func (d typeDoc) matches(refType reflect.Type) bool {
+	if !d.found || len(d.fieldNames) != refType.NumField() {
+		return false
+	}
+	for i, name := range d.fieldNames {
+		if refType.Field(i).Name != name {
+			return false
+		}
+	}
+	return true
+}
+
+// Parses the source of the package with the supplied import path, returning
+// the doc comments and array length constants of every struct type declared at
+// the package level. Test files that belong to the package are included so
+// that types declared in them are found as well.
+func loadPkgDocs(pkgPath string) (map[string]typeDoc, error) {
+	pkg, err := build.Import(pkgPath, ".", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := map[string]typeDoc{}
+	consts := map[string]ast.Expr{}
+	fset := token.NewFileSet()
+	for _, name := range slices.Concat(
+		pkg.GoFiles, pkg.CgoFiles, pkg.TestGoFiles,
+	) {
+		f, err := parser.ParseFile(
+			fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if ok && gen.Tok == token.CONST {
+				addConstExprs(consts, gen)
+			}
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				doc := typeSpec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				rv[typeSpec.Name.Name] = structDocs(doc, structType)
+			}
+		}
+	}
+
+	for _, doc := range rv {
+		for _, lens := range doc.arrayLens {
+			for i := range lens {
+				val, ok := constInt(consts, lens[i].name, map[string]bool{})
+				if !ok {
+					lens[i].name = ""
+				}
+				lens[i].val = val
+			}
+		}
+	}
+	return rv, nil
+}
+
+// Adds the value expression of every constant in the supplied declaration to
+// the supplied map. Constants that repeat the expression of a previous line,
+// which is how iota is normally used, are left out.
+func addConstExprs(consts map[string]ast.Expr, gen *ast.GenDecl) {
+	for _, spec := range gen.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		if len(valueSpec.Values) != len(valueSpec.Names) {
+			continue
+		}
+		for i, name := range valueSpec.Names {
+			consts[name.Name] = valueSpec.Values[i]
+		}
+	}
+}
+
+// Returns the value of the package level constant with the supplied name if it
+// is an integer that can be worked out from the source of the package alone.
+// The seen map guards against constants that refer to themselves.
+func constInt(
+	consts map[string]ast.Expr,
+	name string,
+	seen map[string]bool,
+) (int64, bool) {
+	expr, ok := consts[name]
+	if !ok || seen[name] {
+		return 0, false
+	}
+	seen[name] = true
+	defer delete(seen, name)
+	val := constant.ToInt(constExprValue(consts, expr, seen))
+	if val.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(val)
+}
+
+// Evaluates the supplied constant expression, returning an unknown value for
+// anything other than literals, other constants, and unary or binary
+// operators.
+func constExprValue(
+	consts map[string]ast.Expr,
+	expr ast.Expr,
+	seen map[string]bool,
+) constant.Value {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0)
+	case *ast.Ident:
+		if val, ok := constInt(consts, e.Name, seen); ok {
+			return constant.MakeInt64(val)
+		}
+	case *ast.ParenExpr:
+		return constExprValue(consts, e.X, seen)
+	case *ast.UnaryExpr:
+		x := constExprValue(consts, e.X, seen)
+		if x.Kind() == constant.Int {
+			return constant.UnaryOp(e.Op, x, 0)
+		}
+	case *ast.BinaryExpr:
+		x := constant.ToInt(constExprValue(consts, e.X, seen))
+		y := constant.ToInt(constExprValue(consts, e.Y, seen))
+		if x.Kind() != constant.Int || y.Kind() != constant.Int {
+			break
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			if s, ok := constant.Uint64Val(y); ok && s < 64 {
+				return constant.Shift(x, e.Op, uint(s))
+			}
+		case token.QUO, token.REM:
+			if constant.Sign(y) == 0 {
+				break
+			}
+			if e.Op == token.QUO {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+			return constant.BinaryOp(x, e.Op, y)
+		case token.ADD, token.SUB, token.MUL, token.AND, token.OR, token.XOR,
+			token.AND_NOT:
+			return constant.BinaryOp(x, e.Op, y)
+		}
+	}
+	return constant.MakeUnknown()
+}
+
+// Returns the doc comments of the supplied struct and its fields. Fields that
+// do not have a doc comment use their line comment, if any.
+func structDocs(doc *ast.CommentGroup, structType *ast.StructType) typeDoc {
+	rv := typeDoc{
+		found:     true,
+		doc:       doc.Text(),
+		fields:    map[string]string{},
+		arrayLens: map[string][]arrayLenConst{},
+	}
+	for _, field := range structType.Fields.List {
+		text := field.Doc.Text()
+		if text == "" {
+			text = field.Comment.Text()
+		}
+		lens := arrayLenConsts(field.Type)
+		for _, name := range field.Names {
+			rv.fieldNames = append(rv.fieldNames, name.Name)
+			rv.fields[name.Name] = text
+			if len(lens) > 0 {
+				rv.arrayLens[name.Name] = lens
+			}
+		}
+		if len(field.Names) == 0 {
+			rv.fieldNames = append(rv.fieldNames, embeddedFieldName(field.Type))
+			rv.fields[embeddedFieldName(field.Type)] = text
+		}
+	}
+	return rv
+}
+
+// Returns the name of the constant used as the length of each dimension of the
+// supplied array type expression, i.e. `[N][2]T` gives `N` and an empty name.
+// Only constants referred to by a plain identifier are returned, so they are
+// declared in the same package as the struct.
+func arrayLenConsts(expr ast.Expr) []arrayLenConst {
+	var rv []arrayLenConst
+	for {
+		arr, ok := expr.(*ast.ArrayType)
+		if !ok || arr.Len == nil {
+			break
+		}
+		name := ""
+		if ident, ok := arr.Len.(*ast.Ident); ok {
+			name = ident.Name
+		}
+		rv = append(rv, arrayLenConst{name: name})
+		expr = arr.Elt
+	}
+	if !slices.ContainsFunc(
+		rv, func(l arrayLenConst) bool { return l.name != "" },
+	) {
+		return nil
+	}
+	return rv
+}
+
+// Returns the name of an embedded field with the supplied type expression,
+// i.e. `Foo` for `*pkg.Foo`.
+func embeddedFieldName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return embeddedFieldName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedFieldName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(e.X)
+	}
+	return ""
+}
+
+// Returns the lines of the supplied doc comment with anything that would end
+// a C block comment escaped. Trailing backslashes are removed from each line
+// because they would continue a `//` comment onto the next line of the header.
+func docLines(doc string) []string {
+	doc = strings.TrimRight(doc, "\n")
+	if doc == "" {
+		return nil
+	}
+	rv := strings.Split(strings.ReplaceAll(doc, "*/", "* /"), "\n")
+	for i, l := range rv {
+		rv[i] = strings.TrimRight(l, " \t\\")
+	}
+	return rv
+}
+
+// Writes the supplied comment lines at the supplied indent in the style set by
+// [Opts.DocComments].
+func (c *CGoStructGen) templateDoc(f *os.File, indent string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	if c.opts.DocComments == DocCommentsDoxygen {
+		fmt.Fprintf(f, "%s/**\n", indent)
+		for _, l := range lines {
+			fmt.Fprintf(f, "%s%s\n", indent, strings.TrimRight(" * "+l, " "))
+		}
+		fmt.Fprintf(f, "%s */\n", indent)
+		return
+	}
+	for _, l := range lines {
+		fmt.Fprintf(f, "%s%s\n", indent, strings.TrimRight("// "+l, " "))
+	}
+}
+
+// Writes the doc comment of the C struct with the supplied name, which also
+// names the Go type the struct was generated from.
+func (c *CGoStructGen) templateStructDoc(f *os.File, structName string) {
+	if c.opts.DocComments == DocCommentsNone {
+		return
+	}
+	refType, ok := c.types[structName]
+	if !ok || refType.Kind() != reflect.Struct || !c.docs[refType].found {
+		return
+	}
+	lines := docLines(c.docs[refType].doc)
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, fmt.Sprintf(
+		"Generated from the Go type %s.%s", refType.PkgPath(), refType.Name(),
+	))
+	c.templateDoc(f, "\t", lines)
+}
@@ -0,0 +1,272 @@
+package sbcgostructgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	// The fields that are shared by every doc test struct.
+	testDocBase struct {
+		// The version of the layout
+		Version uint8
+	}
+
+	// A point in 2D space.
+	//
+	// Used to test that doc comments are carried into the header.
+	testDocPoint struct {
+		testDocBase `cgo:"embed=flatten"`
+		// The horizontal position
+		X int32
+		Y int32 // The vertical position
+		// A comment that spans
+		// multiple lines and ends a */ block
+		Z        int32
+		Untagged int32
+	}
+)
+
+// Points that are connected in order.
+type testDocPath struct {
+	// The first point
+	Start testDocPoint
+	Ptr   *testDocPoint
+}
+
+func TestLoadPkgDocs(t *testing.T) {
+	docs, err := loadPkgDocs(
+		"github.com/barbell-math/smoothbrain-cgostructgen",
+	)
+	sbtest.Nil(t, err)
+	sbtest.Eq(t,
+		"A point in 2D space.\n\nUsed to test that doc comments are carried into the header.\n",
+		docs["testDocPoint"].doc,
+	)
+	sbtest.Eq(t, "The horizontal position\n", docs["testDocPoint"].fields["X"])
+	sbtest.Eq(t, "The vertical position\n", docs["testDocPoint"].fields["Y"])
+	sbtest.Eq(t, "", docs["testDocPoint"].fields["Untagged"])
+	sbtest.Eq(t, "", docs["testDocPoint"].fields["testDocBase"])
+	sbtest.Eq(t, "Points that are connected in order.\n", docs["testDocPath"].doc)
+
+	_, err = loadPkgDocs("github.com/barbell-math/not-a-real-package")
+	sbtest.NotNil(t, err)
+}
+
+func TestConstInt(t *testing.T) {
+	consts := map[string]ast.Expr{}
+	for name, expr := range map[string]string{
+		"a": "4",
+		"b": "(a + a) * 2",
+		"c": "1 << b / 4",
+		"d": "-a % 3",
+		"e": "b / 0",
+		"f": "f + 1",
+		"g": `"str"`,
+		"h": "len(g)",
+	} {
+		var err error
+		consts[name], err = parser.ParseExpr(expr)
+		sbtest.Nil(t, err)
+	}
+	for name, exp := range map[string]int64{"a": 4, "b": 16, "c": 16384, "d": -1} {
+		val, ok := constInt(consts, name, map[string]bool{})
+		sbtest.True(t, ok)
+		sbtest.Eq(t, exp, val)
+	}
+	for _, name := range []string{"e", "f", "g", "h", "missing"} {
+		_, ok := constInt(consts, name, map[string]bool{})
+		sbtest.False(t, ok)
+	}
+}
+
+func TestGenerateForDocComments(t *testing.T) {
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[testDocPath](res))
+	sbtest.Eq(t, 0, len(res.docs))
+	sbtest.Eq(t, "", res.structs["testDocPath"][0].doc)
+
+	res = New(Opts{DocComments: DocCommentsPlain})
+	sbtest.Nil(t, GenerateFor[testDocPath](res))
+	sbtest.Eq(t, 3, len(res.docs))
+	sbtest.Eq(t, "The first point\n", res.structs["testDocPath"][0].doc)
+	sbtest.Eq(t, "", res.structs["testDocPath"][1].doc)
+	sbtest.Eq(t,
+		"The version of the layout\n", res.structs["testDocPoint"][0].doc,
+	)
+
+	type local struct {
+		// Not visible to the parser
+		F1 int32
+	}
+	res = New(Opts{DocComments: DocCommentsPlain})
+	sbtest.Nil(t, GenerateFor[local](res))
+	sbtest.Eq(t, "", res.structs["local"][0].doc)
+	sbtest.False(t, res.docs[reflect.TypeFor[local]()].found)
+}
+
+func TestGenerateForDocCommentsShadowedType(t *testing.T) {
+	type testDocPath struct {
+		Other int32
+	}
+	res := New(Opts{DocComments: DocCommentsPlain})
+	sbtest.Nil(t, GenerateFor[testDocPath](res))
+	sbtest.Eq(t, "", res.structs["testDocPath"][0].doc)
+	sbtest.False(t, res.docs[reflect.TypeFor[testDocPath]()].found)
+	err := res.WriteTo("./bs/testData/docCommentsShadowed.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/docCommentsShadowed.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct testDocPath{
+		int32_t Other;
+	} testDocPath_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, exp, string(data))
+}
+
+func TestDocLines(t *testing.T) {
+	sbtest.SlicesMatch(t, nil, docLines(""))
+	sbtest.SlicesMatch(t, []string{"a"}, docLines("a\n"))
+	sbtest.SlicesMatch(t, []string{"a", "", "b * /"}, docLines("a\n\nb */\n"))
+	sbtest.SlicesMatch(t,
+		[]string{`C:\dir`, "b"}, docLines("C:\\dir\\ \\\nb\\\n"),
+	)
+}
+
+func TestWriteToDocCommentsPlain(t *testing.T) {
+	res := New(Opts{DocComments: DocCommentsPlain})
+	sbtest.Nil(t, GenerateFor[testDocPath](res))
+	err := res.WriteTo("./bs/testData/docCommentsPlain.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/docCommentsPlain.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	// A point in 2D space.
+	//
+	// Used to test that doc comments are carried into the header.
+	//
+	// Generated from the Go type github.com/barbell-math/smoothbrain-cgostructgen.testDocPoint
+	typedef struct testDocPoint{
+		// The version of the layout
+		uint8_t Version;
+		// The horizontal position
+		int32_t X;
+		// The vertical position
+		int32_t Y;
+		// A comment that spans
+		// multiple lines and ends a * / block
+		int32_t Z;
+		int32_t Untagged;
+	} testDocPoint_t;
+
+	// Points that are connected in order.
+	//
+	// Generated from the Go type github.com/barbell-math/smoothbrain-cgostructgen.testDocPath
+	typedef struct testDocPath{
+		// The first point
+		testDocPoint_t Start;
+		testDocPoint_t* Ptr;
+	} testDocPath_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, exp, string(data))
+}
+
+func TestWriteToDocCommentsDoxygen(t *testing.T) {
+	res := New(Opts{DocComments: DocCommentsDoxygen})
+	sbtest.Nil(t, GenerateFor[testDocPath](res))
+	err := res.WriteTo("./bs/testData/docCommentsDoxygen.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/docCommentsDoxygen.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	/**
+	 * A point in 2D space.
+	 *
+	 * Used to test that doc comments are carried into the header.
+	 *
+	 * Generated from the Go type github.com/barbell-math/smoothbrain-cgostructgen.testDocPoint
+	 */
+	typedef struct testDocPoint{
+		uint8_t Version; ///< The version of the layout
+		int32_t X; ///< The horizontal position
+		int32_t Y; ///< The vertical position
+		/**
+		 * A comment that spans
+		 * multiple lines and ends a * / block
+		 */
+		int32_t Z;
+		int32_t Untagged;
+	} testDocPoint_t;
+
+	/**
+	 * Points that are connected in order.
+	 *
+	 * Generated from the Go type github.com/barbell-math/smoothbrain-cgostructgen.testDocPath
+	 */
+	typedef struct testDocPath{
+		testDocPoint_t Start; ///< The first point
+		testDocPoint_t* Ptr;
+	} testDocPath_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, exp, string(data))
+}
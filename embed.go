@@ -0,0 +1,91 @@
+package sbcgostructgen
+
+import (
+	"reflect"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// Describes how embedded structs are written in C. See
+	// [Opts.EmbeddedFields].
+	EmbeddedFieldMode int
+)
+
+const (
+	// Embedded structs are written as a member named after the embedded
+	// type, i.e. `Base_t Base;`.
+	EmbeddedFieldsNested EmbeddedFieldMode = iota
+	// Embedded structs are written as C11 anonymous struct members, i.e.
+	// `struct{ int32_t ID; };`, so the promoted fields are accessed in C the
+	// same way they are in Go.
+	EmbeddedFieldsAnonymous
+	// The fields of embedded structs are written directly in the parent
+	// struct as if they had been declared there.
+	EmbeddedFieldsFlatten
+)
+
+var (
+	embedTagModes = map[string]EmbeddedFieldMode{
+		"nested":    EmbeddedFieldsNested,
+		"anonymous": EmbeddedFieldsAnonymous,
+		"flatten":   EmbeddedFieldsFlatten,
+	}
+)
+
+// Returns true if the supplied field is an embedded struct whose fields can be
+// promoted into the parent C struct.
+func (c *CGoStructGen) promotable(field reflect.StructField) bool {
+	if !field.Anonymous || field.Type.Kind() != reflect.Struct {
+		return false
+	}
+	if _, ok := c.opts.TypeOverrides[field.Type]; ok {
+		return false
+	}
+	if _, _, ok := cTyperFor(field.Type); ok {
+		return false
+	}
+	return true
+}
+
+// Returns the mode the supplied field should be written with, accounting for
+// the `embed` option of the `cgo` tag. Fields that are not promotable are
+// always nested.
+func (c *CGoStructGen) embedMode(
+	field reflect.StructField,
+	tag cgoTag,
+) EmbeddedFieldMode {
+	if !c.promotable(field) {
+		return EmbeddedFieldsNested
+	}
+	if tag.embed != "" {
+		return embedTagModes[tag.embed]
+	}
+	return c.opts.EmbeddedFields
+}
+
+// Checks that the `embed` option of the `cgo` tag is only used on embedded
+// structs and is not combined with options that describe a single field.
+func (c *CGoStructGen) checkEmbedTag(
+	field reflect.StructField,
+	tag cgoTag,
+) error {
+	if tag.embed == "" {
+		return nil
+	}
+	if !c.promotable(field) {
+		return sberr.Wrap(
+			InvalidTagErr,
+			"The embed option can only be used on embedded structs",
+		)
+	}
+	if embedTagModes[tag.embed] != EmbeddedFieldsNested &&
+		(tag.name != "" || tag._type != "") {
+		return sberr.Wrap(
+			InvalidTagErr,
+			"The name and type options cannot be used with embed=%s",
+			tag.embed,
+		)
+	}
+	return nil
+}
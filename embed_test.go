@@ -0,0 +1,191 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForEmbeddedNested(t *testing.T) {
+	type Base struct{ ID int32 }
+	type s1 struct {
+		Base
+		f1 int8
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 2, len(res.structs))
+	sbtest.Eq(t, "Base_t Base", res.structs["s1"][0].String())
+}
+
+func TestGenerateForEmbeddedFlatten(t *testing.T) {
+	type Inner struct {
+		A int8
+		B int64
+	}
+	type Base struct {
+		ID int32
+		Inner
+	}
+	type s1 struct {
+		f1 int8
+		Base
+		f2 int16
+	}
+	res := New(Opts{EmbeddedFields: EmbeddedFieldsFlatten})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	sbtest.Eq(t, 1, len(res.structs))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 7, len(fields))
+	names := []string{}
+	offsets := []uintptr{}
+	for _, f := range fields {
+		names = append(names, f.String())
+		offsets = append(offsets, f.offset)
+	}
+	// The embedded structs keep their Go layout, so explicit padding is
+	// needed where the C compiler would otherwise pack the fields together
+	sbtest.SlicesMatch(t,
+		[]string{
+			"int8_t f1", "uint8_t _reserved_1[7]", "int32_t ID",
+			"uint8_t _reserved_12[4]", "int8_t A", "int64_t B", "int16_t f2",
+		},
+		names,
+	)
+	sbtest.SlicesMatch(t, []uintptr{0, 1, 8, 12, 16, 24, 32}, offsets)
+}
+
+func TestGenerateForEmbeddedAnonymous(t *testing.T) {
+	type Base struct {
+		ID   int32
+		Name [4]uint8
+	}
+	type s1 struct {
+		f1 int8
+		Base
+	}
+	res := New(Opts{EmbeddedFields: EmbeddedFieldsAnonymous})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	sbtest.Eq(t, 1, len(res.structs))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 2, len(fields))
+	sbtest.Eq(t, "struct{ int32_t ID; uint8_t Name[4]; }", fields[1].String())
+	sbtest.Eq(t, 4, fields[1].offset)
+	sbtest.Eq(t, 8, fields[1].size)
+}
+
+func TestGenerateForEmbeddedTagOverride(t *testing.T) {
+	type Base struct{ ID int32 }
+	type Other struct{ Val int32 }
+	type s1 struct {
+		Base  `cgo:"embed=flatten"`
+		Other `cgo:"embed=nested"`
+	}
+	res := New(Opts{EmbeddedFields: EmbeddedFieldsAnonymous})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "int32_t ID", fields[0].String())
+	sbtest.Eq(t, "Other_t Other", fields[1].String())
+}
+
+func TestGenerateForEmbeddedInvalidTag(t *testing.T) {
+	type s1 struct {
+		f1 int32 `cgo:"embed=flatten"`
+	}
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTagErr, err)
+
+	type Base struct{ ID int32 }
+	type s2 struct {
+		Base `cgo:"embed=inline"`
+	}
+	err = GenerateFor[s2](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTagErr, err)
+
+	type s3 struct {
+		Base `cgo:"embed=flatten,name=b"`
+	}
+	err = GenerateFor[s3](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTagErr, err)
+
+	type s4 struct {
+		*Base `cgo:"embed=flatten"`
+	}
+	err = GenerateFor[s4](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTagErr, err)
+}
+
+func TestGenerateForEmbeddedConflict(t *testing.T) {
+	type A struct{ ID int32 }
+	type B struct{ ID int64 }
+	type s1 struct {
+		A
+		B
+	}
+	err := GenerateFor[s1](New(Opts{EmbeddedFields: EmbeddedFieldsFlatten}))
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+	err = GenerateFor[s1](New(Opts{EmbeddedFields: EmbeddedFieldsAnonymous}))
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+	err = GenerateFor[s1](New(Opts{EmbeddedFields: EmbeddedFieldsNested}))
+	sbtest.Nil(t, err)
+
+	type s2 struct {
+		A
+		ID int8
+	}
+	err = GenerateFor[s2](New(Opts{EmbeddedFields: EmbeddedFieldsFlatten}))
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
+
+func TestWriteEmbeddedFields(t *testing.T) {
+	type Header struct {
+		Version uint8
+		Flags   [2]uint8
+	}
+	type Base struct{ ID int32 }
+	type Msg struct {
+		Header `cgo:"embed=anonymous"`
+		Base   `cgo:"embed=flatten"`
+		Len    uint16
+	}
+	res := New(Opts{EmitArrayLenMacros: true})
+	err := GenerateFor[Msg](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/embedded.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/embedded.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	#define MSG_FLAGS_LEN 2
+	typedef struct Msg{
+		struct{
+			uint8_t Version;
+			uint8_t Flags[MSG_FLAGS_LEN];
+		};
+		int32_t ID;
+		uint16_t Len;
+	} Msg_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
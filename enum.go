@@ -0,0 +1,212 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// The set of types that can be added with [GenerateEnum]. This matches the
+	// types that are generated by tools such as go-enum when a sized integer
+	// type is used.
+	Enum interface {
+		~int8 | ~int16 | ~int32 | ~int64 |
+			~uint8 | ~uint16 | ~uint32 | ~uint64
+		fmt.Stringer
+	}
+
+	enumValue struct {
+		// The name of the C enum constant
+		name string
+		// The value returned by the Go String method
+		str string
+		val int64
+	}
+
+	cEnum struct {
+		name   string
+		_type  string
+		values []enumValue
+	}
+)
+
+var nonCIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Adds the supplied enum type to the struct generator. In C the enum will be
+// represented by a typedef of the fixed width integer type that matches the Go
+// type along with a C enum that holds all of the supplied values. Each enum
+// constant is named `<Type>_<value>` where value is the result of calling
+// String on the value with any characters that are not valid in a C identifier
+// replaced with underscores. The values are written in the order supplied, so
+// with go-enum the generated `<Type>Values()` function can be passed directly.
+//
+// If [Opts.EmitEnumNames] is true a `const char* <Type>_name(<Type>_t v)`
+// function will also be written that returns the same strings as the Go
+// String method.
+//
+// Any struct fields of type T will use the enum typedef rather than the plain
+// integer type, regardless of whether the structs were added through
+// [GenerateFor] before or after this function is called. It is safe to call
+// this function from multiple goroutines.
+func GenerateEnum[T Enum](c *CGoStructGen, values ...T) error {
+	var err error
+	var enum cEnum
+	var cInclude include
+	refType := reflect.TypeFor[T]()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	saved := c.genState.clone()
+
+	if refType.Name() == "" {
+		err = sberr.Wrap(
+			AnonymousNameErr, "Enum types must be named, got %s", refType,
+		)
+		goto errExit
+	}
+	enum = cEnum{
+		name:   c.typedefRename(refType.Name(), true),
+		values: make([]enumValue, 0, len(values)),
+	}
+	enum._type, cInclude, _ = c.kindCType(refType.Kind())
+	if err = c.checkTypedefName(refType, enum.name); err != nil {
+		goto errExit
+	}
+	if err = c.checkTypeIdent(enum.name, true); err != nil {
+		goto errExit
+	}
+	if err = c.claimTypeNames(refType, c.cTag(enum.name)); err != nil {
+		goto errExit
+	}
+	for _, v := range values {
+		iterVal := enumValue{str: v.String()}
+		iterVal.name = c.cIdent(
+			c.cTag(enum.name) + "_" +
+				nonCIdentChars.ReplaceAllString(iterVal.str, "_"),
+		)
+		if err = checkIdent(iterVal.name); err != nil {
+			err = sberr.Wrap(err, "enum %s", enum.name)
+			goto errExit
+		}
+
+		inRange := false
+		refVal := reflect.ValueOf(v)
+		if refVal.CanInt() {
+			iterVal.val = refVal.Int()
+			inRange = iterVal.val >= math.MinInt32 &&
+				iterVal.val <= math.MaxInt32
+		} else if refVal.Uint() <= math.MaxInt32 {
+			iterVal.val = int64(refVal.Uint())
+			inRange = true
+		}
+		if !inRange {
+			err = sberr.Wrap(
+				InvalidTypeErr,
+				"C enum values must fit in an int, enum %s, value %s",
+				enum.name, iterVal.str,
+			)
+			goto errExit
+		}
+
+		if idx := slices.IndexFunc(enum.values, func(o enumValue) bool {
+			return o.name == iterVal.name
+		}); idx >= 0 {
+			if enum.values[idx].val == iterVal.val {
+				// The value was supplied more than once, nothing to add
+				continue
+			}
+			err = sberr.Wrap(
+				DuplicateNameErr,
+				"The C name %s is used by multiple values, enum %s",
+				iterVal.name, enum.name,
+			)
+			goto errExit
+		}
+		enum.values = append(enum.values, iterVal)
+	}
+
+	c.enums[refType] = enum
+	if cInclude != "" {
+		c.includes[cInclude] = struct{}{}
+	}
+	if err = c.checkComplexIdents(); err != nil {
+		goto errExit
+	}
+	if err = c.checkConstNames(); err != nil {
+		goto errExit
+	}
+	// Structs that were already added wrote the type as a plain typedef
+	maps.DeleteFunc(c.typedefs, func(_ string, t reflect.Type) bool {
+		return t == refType
+	})
+	c.stale = true
+
+errExit:
+	if err != nil {
+		c.genState = saved
+		if c.opts.ExitOnErr {
+			log.Fatal(err)
+		}
+	}
+	return err
+}
+
+func (c *CGoStructGen) sortedEnums() []cEnum {
+	rv := make([]cEnum, 0, len(c.enums))
+	for _, e := range c.enums {
+		rv = append(rv, e)
+	}
+	slices.SortFunc(rv, func(l cEnum, r cEnum) int {
+		return strings.Compare(l.name, r.name)
+	})
+	return rv
+}
+
+func (c *CGoStructGen) templateCEnums(f *os.File, g *headerGroup) {
+	for _, enum := range c.sortedEnums() {
+		if !g.has(enum.name) {
+			continue
+		}
+		tag, typedef := c.cTag(enum.name), c.cTypedef(enum.name)
+		fmt.Fprintf(f, "\ttypedef %s %s;\n", enum._type, typedef)
+		if tag == typedef {
+			// C++ does not allow an enum tag to share the name of a typedef
+			// for a different type, the tag is not needed to refer to the
+			// values so it is dropped
+			f.WriteString("\tenum{\n")
+		} else {
+			fmt.Fprintf(f, "\tenum %s{\n", tag)
+		}
+		for _, v := range enum.values {
+			fmt.Fprintf(f, "\t\t%s = %d,\n", v.name, v.val)
+		}
+		f.WriteString("\t};\n")
+
+		if c.opts.EmitEnumNames {
+			fmt.Fprintf(
+				f, "\tstatic inline const char* %s_name(%s v) {\n",
+				tag, typedef,
+			)
+			f.WriteString("\t\tswitch (v) {\n")
+			for _, v := range enum.values {
+				fmt.Fprintf(
+					f, "\t\t\tcase %s: return %s;\n",
+					v.name, cStringLiteral(v.str),
+				)
+			}
+			fmt.Fprintf(f, "\t\t\tdefault: return \"%s(unknown)\";\n", enum.name)
+			f.WriteString("\t\t}\n")
+			f.WriteString("\t}\n")
+		}
+		f.WriteString("\n")
+	}
+}
@@ -0,0 +1,223 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	testColor   int8
+	testLarge   uint32
+	testSymbol  int16
+	testEscaped uint8
+)
+
+const (
+	testColorRed testColor = iota
+	testColorGreen
+	testColorBlue
+	testColorDefault = testColorRed
+)
+
+func (t testColor) String() string {
+	switch t {
+	case testColorRed:
+		return "Red"
+	case testColorGreen:
+		return "Green"
+	case testColorBlue:
+		return "Blue"
+	}
+	return fmt.Sprintf("testColor(%d)", t)
+}
+
+func (t testLarge) String() string {
+	return fmt.Sprintf("L%d", uint32(t))
+}
+
+func (t testSymbol) String() string {
+	if t == 0 {
+		return "a-b"
+	}
+	return "a.b"
+}
+
+func (t testEscaped) String() string {
+	if t == 0 {
+		return "na\u00efve\"x\""
+	}
+	return "a\tb\\"
+}
+
+func TestGenerateEnum(t *testing.T) {
+	res := New(Opts{})
+	err := GenerateEnum(res, testColorRed, testColorGreen, testColorBlue)
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, 1, len(res.enums))
+
+	enum := res.sortedEnums()[0]
+	sbtest.Eq(t, "testColor", enum.name)
+	sbtest.Eq(t, "int8_t", enum._type)
+	sbtest.SlicesMatch(t,
+		[]enumValue{
+			{name: "testColor_Red", str: "Red", val: 0},
+			{name: "testColor_Green", str: "Green", val: 1},
+			{name: "testColor_Blue", str: "Blue", val: 2},
+		},
+		enum.values,
+	)
+}
+
+func TestGenerateEnumOutOfRange(t *testing.T) {
+	res := New(Opts{})
+	err := GenerateEnum(res, testLarge(1), testLarge(1<<31))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+}
+
+func TestGenerateEnumDuplicateName(t *testing.T) {
+	res := New(Opts{})
+	err := GenerateEnum(res, testSymbol(0), testSymbol(1))
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
+
+func TestGenerateForEnumField(t *testing.T) {
+	type s1 struct {
+		f1 testColor
+		f2 [2]testColor
+		f3 int8
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateEnum(res, testColorRed, testColorGreen))
+	sbtest.Nil(t, GenerateFor[s1](res))
+	structFieldsMatch(t,
+		[]structField{
+			{_type: "testColor_t", name: "f1", offset: 0, size: 1, align: 1},
+			{
+				_type: "testColor_t", name: "f2",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{2}},
+				offset:       1, size: 2, align: 1,
+			},
+			{_type: "int8_t", name: "f3", offset: 3, size: 1, align: 1},
+		},
+		res.structs["s1"],
+	)
+}
+
+func TestGenerateEnumAfterStruct(t *testing.T) {
+	type s1 struct {
+		f1 testColor
+		f2 int8
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Nil(t, GenerateEnum(res, testColorRed, testColorGreen))
+	structFieldsMatch(t,
+		[]structField{
+			{_type: "testColor_t", name: "f1", offset: 0, size: 1, align: 1},
+			{_type: "int8_t", name: "f2", offset: 1, size: 1, align: 1},
+		},
+		res.structs["s1"],
+	)
+	sbtest.Eq(t, 0, len(res.typedefs))
+	sbtest.Eq(t, 1, len(res.enums))
+}
+
+func TestWriteEnum(t *testing.T) {
+	type s1 struct{ f1 testColor }
+	res := New(Opts{EmitEnumNames: true})
+	err := GenerateEnum(
+		res, testColorRed, testColorGreen, testColorBlue, testColorDefault,
+	)
+	sbtest.Nil(t, err)
+	err = GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/enum.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/enum.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef int8_t testColor_t;
+	enum testColor{
+		testColor_Red = 0,
+		testColor_Green = 1,
+		testColor_Blue = 2,
+	};
+	static inline const char* testColor_name(testColor_t v) {
+		switch (v) {
+			case testColor_Red: return "Red";
+			case testColor_Green: return "Green";
+			case testColor_Blue: return "Blue";
+			default: return "testColor(unknown)";
+		}
+	}
+
+	typedef struct s1{
+		testColor_t f1;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestWriteEnumNamesEscaped(t *testing.T) {
+	res := New(Opts{EmitEnumNames: true})
+	err := GenerateEnum(res, testEscaped(0), testEscaped(1))
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/enumEscaped.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/enumEscaped.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef uint8_t testEscaped_t;
+	enum testEscaped{
+		testEscaped_na_ve_x_ = 0,
+		testEscaped_a_b_ = 1,
+	};
+	static inline const char* testEscaped_name(testEscaped_t v) {
+		switch (v) {
+			case testEscaped_na_ve_x_: return "na\303\257ve\"x\"";
+			case testEscaped_a_b_: return "a\011b\\";
+			default: return "testEscaped(unknown)";
+		}
+	}
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
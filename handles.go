@@ -0,0 +1,276 @@
+package sbcgostructgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"maps"
+	"os"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+// Returns the name of the C handle type for the supplied map, interface, or
+// func type, i.e. `CallbacksHandle` for `type Callbacks map[string]func()`.
+// The first letter is upper cased so that the Go type written by
+// [CGoStructGen.WriteGoHandlesTo] is exported.
+func (c *CGoStructGen) handleName(refType reflect.Type) string {
+	name := c.typeCase(
+		exportedName(c.typedefRename(refType.Name(), false)) + "Handle",
+	)
+	if c.opts.Naming.TypedefPattern != "" {
+		name = c.cTypedef(name)
+	}
+	return name
+}
+
+// Returns the supplied name with its first letter upper cased.
+func exportedName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// Returns the indefinite article for the supplied word, i.e. `an` for `error`.
+func article(word string) string {
+	if word != "" && strings.ContainsRune("aeiouAEIOU", rune(word[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// Returns the type of the value the supplied type is a handle to and true if
+// the supplied type is a handle. Handles are named uintptr types with the same
+// Value and Delete methods as runtime/cgo.Handle, which includes
+// runtime/cgo.Handle itself and the types written by
+// [CGoStructGen.WriteGoHandlesTo]. Handles are only recognized when
+// [Opts.HandleFields] is true.
+func (c *CGoStructGen) handleValueType(refType reflect.Type) (reflect.Type, bool) {
+	if !c.opts.HandleFields || refType.Kind() != reflect.Uintptr ||
+		refType.Name() == "" {
+		return nil, false
+	}
+	value, ok := refType.MethodByName("Value")
+	if !ok || value.Type.NumIn() != 1 || value.Type.NumOut() != 1 {
+		return nil, false
+	}
+	del, ok := refType.MethodByName("Delete")
+	if !ok || del.Type.NumIn() != 1 || del.Type.NumOut() != 0 {
+		return nil, false
+	}
+	return value.Type.Out(0), true
+}
+
+// Returns the C type of a field that holds a handle to a value of the supplied
+// type. Handles to unnamed types, such as the `any` held by a
+// runtime/cgo.Handle, are written as a plain `uintptr_t`.
+func (c *CGoStructGen) handleCType(valueType reflect.Type) string {
+	if valueType.Name() == "" {
+		return "uintptr_t"
+	}
+	return c.handleName(valueType)
+}
+
+// Checks that the supplied type, which is the type of the value a handle
+// refers to, can be represented by a handle and registers the handle type.
+func (c *CGoStructGen) checkHandle(refType reflect.Type, fieldName string) error {
+	if !c.opts.HandleFields {
+		return sberr.Wrap(
+			InvalidTypeErr,
+			"Cannot represent a Go %s by a handle unless handle fields are enabled, field %s",
+			refType.Kind(), fieldName,
+		)
+	}
+	if c.opts.PODOnly {
+		return sberr.Wrap(
+			NonPODTypeErr,
+			"A %s is represented by a handle which is not allowed when only plain data is allowed, field %s",
+			refType.Kind(), fieldName,
+		)
+	}
+	if refType.Name() == "" {
+		// Written as a plain uintptr_t, so there is no handle type to add
+		return nil
+	}
+
+	name := c.handleName(refType)
+	if err := checkIdent(name); err != nil {
+		return sberr.Wrap(err, "field %s", fieldName)
+	}
+	if other, ok := c.handles[name]; ok && other != refType {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The C handle %s is used by both %s and %s, field %s",
+			name, other, refType, fieldName,
+		)
+	}
+	if err := c.claimTypeNames(refType, name); err != nil {
+		return sberr.Wrap(err, "field %s", fieldName)
+	}
+	c.handles[name] = refType
+	return nil
+}
+
+// Adds a handle for the supplied named map, interface, or func type to the
+// struct generator. This allows the Go helpers for the handle to be written
+// with [CGoStructGen.WriteGoHandlesTo] before any struct uses them. Struct
+// fields that hold the written handle type are added automatically by
+// [GenerateFor], see [Opts.HandleFields]. It is safe to call this function
+// from multiple goroutines.
+func GenerateHandle[T any](c *CGoStructGen) error {
+	var err error
+	refType := reflect.TypeFor[T]()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	saved := c.genState.clone()
+
+	switch refType.Kind() {
+	case reflect.Map, reflect.Func, reflect.Interface:
+	default:
+		err = sberr.Wrap(
+			InvalidTypeErr,
+			"Expected a map, func, or interface, got %s", refType.Kind(),
+		)
+		goto errExit
+	}
+	if refType.Name() == "" {
+		err = sberr.Wrap(
+			AnonymousNameErr,
+			"A %s must be a named type to be represented by a handle",
+			refType.Kind(),
+		)
+		goto errExit
+	}
+	if err = c.checkHandle(refType, ""); err != nil {
+		goto errExit
+	}
+	err = c.checkConstNames()
+
+errExit:
+	if err != nil {
+		c.genState = saved
+		if c.opts.ExitOnErr {
+			log.Fatal(err)
+		}
+	}
+	return err
+}
+
+func (c *CGoStructGen) templateHandles(f *os.File, g *headerGroup) {
+	names := slices.DeleteFunc(
+		slices.Collect(maps.Keys(c.handles)),
+		func(name string) bool { return !g.has(name) },
+	)
+	if len(names) == 0 {
+		return
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(f, "\ttypedef uintptr_t %s;\n", name)
+	}
+	f.WriteString("\n")
+}
+
+// Writes the Go helpers for all of the handle types that were added through
+// calls to [GenerateHandle] and [GenerateFor] to the specified file. See
+// [Opts.HandleFields]. For each handle a Go type with the same name as the C
+// handle type is written along with functions to create, resolve, and delete
+// handles of that type. The first letter of the names is upper cased if it is
+// not already, so the Go type and its functions are always exported.
+// The pkgName and pkgPath arguments are the name and import path of the
+// package the file belongs to.
+func (c *CGoStructGen) WriteGoHandlesTo(
+	file string,
+	pkgName string,
+	pkgPath string,
+) error {
+	var err error
+	var src []byte
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	src, err = c.goHandles(pkgName, pkgPath)
+	if err != nil {
+		goto errExit
+	}
+	err = os.WriteFile(file, src, 0644)
+
+errExit:
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+func (c *CGoStructGen) goHandles(pkgName string, pkgPath string) ([]byte, error) {
+	names := slices.Collect(maps.Keys(c.handles))
+	slices.Sort(names)
+
+	imports := map[string]string{"runtime/cgo": ""}
+	goTypes := map[string]string{}
+	for _, name := range names {
+		refType := c.handles[name]
+		switch {
+		case refType.PkgPath() == pkgPath:
+			goTypes[name] = refType.Name()
+		case refType.PkgPath() == "":
+			// Predeclared types, such as error
+			goTypes[name] = refType.String()
+		default:
+			importName, _, _ := strings.Cut(refType.String(), ".")
+			imports[refType.PkgPath()] = importName
+			goTypes[name] = refType.String()
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cgoStructGen - DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	buf.WriteString("import (\n")
+	importPaths := slices.Collect(maps.Keys(imports))
+	slices.Sort(importPaths)
+	for _, i := range importPaths {
+		if imports[i] == "" || imports[i] == path.Base(i) {
+			fmt.Fprintf(&buf, "\t%q\n", i)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", imports[i], i)
+		}
+	}
+	buf.WriteString(")\n")
+
+	for _, name := range names {
+		goName, goType := exportedName(name), goTypes[name]
+		fmt.Fprintf(&buf, `
+// A handle to %[3]s %[2]s value that can be passed to C as %[4]s %[1]s.
+type %[1]s cgo.Handle
+
+// Creates a new handle for the supplied value. The handle must be deleted
+// once C no longer uses it.
+func New%[1]s(v %[2]s) %[1]s {
+	return %[1]s(cgo.NewHandle(v))
+}
+
+// Returns the value the handle refers to.
+func (h %[1]s) Value() %[2]s {
+	v, _ := cgo.Handle(h).Value().(%[2]s)
+	return v
+}
+
+// Deletes the handle, after which it must not be used.
+func (h %[1]s) Delete() {
+	cgo.Handle(h).Delete()
+}
+`,
+			goName, goType, article(goType), article(goName),
+		)
+	}
+	return format.Source(buf.Bytes())
+}
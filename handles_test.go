@@ -0,0 +1,259 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime/cgo"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	testCallbacks map[string]int32
+	testVisitor   func(v int32) bool
+
+	// The same as the types written by WriteGoHandlesTo
+	TestCallbacksHandle cgo.Handle
+	TestVisitorHandle   cgo.Handle
+	ErrorHandle         cgo.Handle
+)
+
+func (h TestCallbacksHandle) Value() testCallbacks {
+	v, _ := cgo.Handle(h).Value().(testCallbacks)
+	return v
+}
+func (h TestCallbacksHandle) Delete() { cgo.Handle(h).Delete() }
+
+func (h TestVisitorHandle) Value() testVisitor {
+	v, _ := cgo.Handle(h).Value().(testVisitor)
+	return v
+}
+func (h TestVisitorHandle) Delete() { cgo.Handle(h).Delete() }
+
+func (h ErrorHandle) Value() error {
+	v, _ := cgo.Handle(h).Value().(error)
+	return v
+}
+func (h ErrorHandle) Delete() { cgo.Handle(h).Delete() }
+
+func TestGenerateForHandlesDisabled(t *testing.T) {
+	type s1 struct{ f1 testCallbacks }
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+}
+
+func TestGenerateForHandlesPODOnly(t *testing.T) {
+	type s1 struct{ f1 TestCallbacksHandle }
+	err := GenerateFor[s1](New(Opts{HandleFields: true, PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	err = GenerateHandle[testCallbacks](
+		New(Opts{HandleFields: true, PODOnly: true}),
+	)
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+}
+
+func TestGenerateForHandlesRawValues(t *testing.T) {
+	type s1 struct{ f1 testCallbacks }
+	err := GenerateFor[s1](New(Opts{HandleFields: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s2 struct{ f1 [2]testVisitor }
+	err = GenerateFor[s2](New(Opts{HandleFields: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s3 struct{ f1 *fmt.Stringer }
+	err = GenerateFor[s3](New(Opts{HandleFields: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s4 struct{ f1 []error }
+	err = GenerateFor[s4](New(Opts{HandleFields: true, SliceStructs: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+}
+
+func TestGenerateHandle(t *testing.T) {
+	err := GenerateHandle[int32](New(Opts{HandleFields: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	err = GenerateHandle[map[string]int32](New(Opts{HandleFields: true}))
+	sbtest.ContainsError(t, AnonymousNameErr, err)
+
+	err = GenerateHandle[testCallbacks](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	res := New(Opts{HandleFields: true})
+	sbtest.Nil(t, GenerateHandle[testCallbacks](res))
+	sbtest.Nil(t, GenerateHandle[error](res))
+	sbtest.Eq(t, 2, len(res.handles))
+	sbtest.Eq(t, reflect.TypeFor[error](), res.handles["ErrorHandle"])
+}
+
+func TestGenerateForHandlesDuplicateName(t *testing.T) {
+	res := New(Opts{HandleFields: true})
+	sbtest.Nil(t, GenerateHandle[testVisitor](res))
+
+	type testVisitor map[int32]int32
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateHandle[testVisitor](res))
+}
+
+func TestGenerateForHandles(t *testing.T) {
+	type s1 struct {
+		f1 TestCallbacksHandle
+		f2 ErrorHandle
+		f3 [2]TestVisitorHandle
+		f4 cgo.Handle
+		f5 int8
+	}
+	res := New(Opts{
+		HandleFields:  true,
+		TypedefRename: map[string]string{"testVisitor": "Visitor"},
+	})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 5, len(fields))
+	sbtest.Eq(t, "TestCallbacksHandle f1", fields[0].String())
+	sbtest.Eq(t, "ErrorHandle f2", fields[1].String())
+	sbtest.Eq(t, 8, fields[1].offset)
+	sbtest.Eq(t, "VisitorHandle f3[2]", fields[2].String())
+	sbtest.Eq(t, 16, fields[2].offset)
+	sbtest.Eq(t, "uintptr_t f4", fields[3].String())
+	sbtest.Eq(t, 32, fields[3].offset)
+	sbtest.Eq(t, "int8_t f5", fields[4].String())
+	sbtest.Eq(t, 3, len(res.handles))
+	sbtest.Eq(t, 0, len(res.typedefs))
+	for _, report := range res.PointerRuleReport() {
+		sbtest.True(t, report.SafeToPassByPointer)
+	}
+}
+
+func TestWriteHandles(t *testing.T) {
+	type s1 struct {
+		f1 TestCallbacksHandle
+		f2 ErrorHandle
+	}
+	res := New(Opts{HandleFields: true})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/handles.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/handles.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef uintptr_t ErrorHandle;
+	typedef uintptr_t TestCallbacksHandle;
+
+	typedef struct s1{
+		TestCallbacksHandle f1;
+		ErrorHandle f2;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestWriteGoHandles(t *testing.T) {
+	res := New(Opts{HandleFields: true})
+	sbtest.Nil(t, GenerateHandle[testCallbacks](res))
+	sbtest.Nil(t, GenerateHandle[fmt.Stringer](res))
+	sbtest.Nil(t, GenerateHandle[error](res))
+	// Files starting with an underscore are ignored by the go tool
+	err := res.WriteGoHandlesTo(
+		"./bs/testData/_handles.go",
+		"sbcgostructgen",
+		"github.com/barbell-math/smoothbrain-cgostructgen",
+	)
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/_handles.go")
+	sbtest.Nil(t, err)
+	exp := `// Code generated by cgoStructGen - DO NOT EDIT.
+
+package sbcgostructgen
+
+import (
+	"fmt"
+	"runtime/cgo"
+)
+
+// A handle to an error value that can be passed to C as an ErrorHandle.
+type ErrorHandle cgo.Handle
+
+// Creates a new handle for the supplied value. The handle must be deleted
+// once C no longer uses it.
+func NewErrorHandle(v error) ErrorHandle {
+	return ErrorHandle(cgo.NewHandle(v))
+}
+
+// Returns the value the handle refers to.
+func (h ErrorHandle) Value() error {
+	v, _ := cgo.Handle(h).Value().(error)
+	return v
+}
+
+// Deletes the handle, after which it must not be used.
+func (h ErrorHandle) Delete() {
+	cgo.Handle(h).Delete()
+}
+
+// A handle to a fmt.Stringer value that can be passed to C as a StringerHandle.
+type StringerHandle cgo.Handle
+
+// Creates a new handle for the supplied value. The handle must be deleted
+// once C no longer uses it.
+func NewStringerHandle(v fmt.Stringer) StringerHandle {
+	return StringerHandle(cgo.NewHandle(v))
+}
+
+// Returns the value the handle refers to.
+func (h StringerHandle) Value() fmt.Stringer {
+	v, _ := cgo.Handle(h).Value().(fmt.Stringer)
+	return v
+}
+
+// Deletes the handle, after which it must not be used.
+func (h StringerHandle) Delete() {
+	cgo.Handle(h).Delete()
+}
+
+// A handle to a testCallbacks value that can be passed to C as a TestCallbacksHandle.
+type TestCallbacksHandle cgo.Handle
+
+// Creates a new handle for the supplied value. The handle must be deleted
+// once C no longer uses it.
+func NewTestCallbacksHandle(v testCallbacks) TestCallbacksHandle {
+	return TestCallbacksHandle(cgo.NewHandle(v))
+}
+
+// Returns the value the handle refers to.
+func (h TestCallbacksHandle) Value() testCallbacks {
+	v, _ := cgo.Handle(h).Value().(testCallbacks)
+	return v
+}
+
+// Deletes the handle, after which it must not be used.
+func (h TestCallbacksHandle) Delete() {
+	cgo.Handle(h).Delete()
+}
+`
+	sbtest.Eq(t, string(data), exp)
+}
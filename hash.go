@@ -0,0 +1,230 @@
+package sbcgostructgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"go/format"
+	"hash/fnv"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+// Returns a stable hash of the layout of the C struct with the supplied name.
+// The hash covers the struct's size along with the name, C type, offset, and
+// size of every field. Structs that are embedded by value, either directly or
+// in an array, contribute their own layout hash so changing a nested struct
+// changes the hash of every struct that contains it. False is returned if no
+// struct with the supplied name was added through [GenerateFor].
+//
+// The returned value matches the `<STRUCT>_LAYOUT_HASH` define that is written
+// when [Opts.EmitLayoutHashes] is true, and the Go constant written by
+// [CGoStructGen.WriteGoLayoutHashesTo], allowing both sides of a runtime
+// handshake to verify they agree on the layout.
+func (c *CGoStructGen) LayoutHash(structName string) (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+
+	if _, ok := c.structs[structName]; !ok {
+		return 0, false
+	}
+	return c.layoutHash(structName), true
+}
+
+// Returns a stable hash of the layout of every struct that was added through
+// [GenerateFor]. The returned value matches the `<HEADER>_LAYOUT_HASH` define
+// that is written when [Opts.EmitLayoutHashes] is true.
+func (c *CGoStructGen) HeaderLayoutHash() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+	return c.headerLayoutHash()
+}
+
+func (c *CGoStructGen) headerLayoutHash() uint64 {
+	h := fnv.New64a()
+	structNames := slices.Collect(maps.Keys(c.structs))
+	slices.Sort(structNames)
+	for _, structName := range structNames {
+		h.Write([]byte(structName))
+		h.Write([]byte{0})
+		h.Write(binary.LittleEndian.AppendUint64(nil, c.layoutHash(structName)))
+	}
+	return h.Sum64()
+}
+
+func (c *CGoStructGen) layoutHash(structName string) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00", structName)
+	if refType, ok := c.types[structName]; ok {
+		fmt.Fprintf(h, "%d\x00", refType.Size())
+	}
+	for _, iterField := range c.structs[structName] {
+		fmt.Fprintf(
+			h, "%s\x00%s\x00%d\x00%d\x00",
+			iterField.name, iterField.typeString(),
+			iterField.offset, iterField.size,
+		)
+		if iterField.structRef != "" && iterField.pntrs == 0 {
+			fmt.Fprintf(h, "%x\x00", c.layoutHash(iterField.structRef))
+		}
+	}
+	return h.Sum64()
+}
+
+// Writes a Go file to the specified path that holds a `<STRUCT>_LAYOUT_HASH`
+// constant for every struct that was added through [GenerateFor] along with a
+// `<HEADER>_LAYOUT_HASH` constant for the entire header. The constants have the
+// same names and values as the defines that are written to the C header when
+// [Opts.EmitLayoutHashes] is true, so Go code can compare them against the
+// values reported by C at runtime. The headerStr argument must match the one
+// passed to [CGoStructGen.WriteTo] and the pkgName argument is the name of the
+// package the file belongs to. A [DuplicateNameErr] is returned if two of the
+// constants would have the same name.
+func (c *CGoStructGen) WriteGoLayoutHashesTo(
+	file string,
+	pkgName string,
+	headerStr string,
+) error {
+	var err error
+	var src []byte
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+
+	if err = c.checkHeaderHashMacro(headerStr); err != nil {
+		goto errExit
+	}
+	src, err = c.goLayoutHashes(pkgName, headerStr)
+	if err != nil {
+		goto errExit
+	}
+	err = os.WriteFile(file, src, 0644)
+
+errExit:
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+func (c *CGoStructGen) goLayoutHashes(
+	pkgName string,
+	headerStr string,
+) ([]byte, error) {
+	structNames := slices.Collect(maps.Keys(c.structs))
+	slices.Sort(structNames)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cgoStructGen - DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	buf.WriteString("// The layout hashes of the generated C structs and header.\n")
+	buf.WriteString("const (\n")
+	for _, structName := range structNames {
+		fmt.Fprintf(
+			&buf, "\t%s uint64 = 0x%016x\n",
+			layoutHashMacro(c.cTag(structName)), c.layoutHash(structName),
+		)
+	}
+	fmt.Fprintf(
+		&buf, "\t%s uint64 = 0x%016x\n",
+		layoutHashMacro(headerStr), c.headerLayoutHash(),
+	)
+	buf.WriteString(")\n")
+	return format.Source(buf.Bytes())
+}
+
+func layoutHashMacro(name string) string {
+	return strings.ToUpper(name) + "_LAYOUT_HASH"
+}
+
+// Claims the layout hash macro of every struct, see [Opts.EmitLayoutHashes].
+// Structs whose names only differ in case would otherwise have the same macro.
+func (c *CGoStructGen) claimLayoutHashNames() error {
+	if !c.opts.EmitLayoutHashes {
+		return nil
+	}
+	for _, structName := range slices.Sorted(maps.Keys(c.structs)) {
+		macro := layoutHashMacro(c.cTag(structName))
+		if other, ok := c.hashMacros[macro]; ok && other != structName {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The layout hash macro %s is used by both %s and %s",
+				macro, other, structName,
+			)
+		}
+		if owner, ok := c.macros[macro]; ok {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The layout hash macro %s has the same name as the array length macro of %s",
+				macro, owner,
+			)
+		}
+		c.hashMacros[macro] = structName
+	}
+	return nil
+}
+
+// Checks that the layout hash macro of the header with the supplied name does
+// not have the same name as the layout hash macro of a struct. The layout hash
+// macros of the structs are checked as well, because they are only claimed
+// when [Opts.EmitLayoutHashes] is true.
+func (c *CGoStructGen) checkHeaderHashMacro(headerStr string) error {
+	macros := map[string]string{}
+	for _, structName := range slices.Sorted(maps.Keys(c.structs)) {
+		macro := layoutHashMacro(c.cTag(structName))
+		if other, ok := macros[macro]; ok {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The layout hash macro %s is used by both %s and %s",
+				macro, other, structName,
+			)
+		}
+		macros[macro] = structName
+	}
+	if other, ok := macros[layoutHashMacro(headerStr)]; ok {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The layout hash macro %s is used by both the header and %s",
+			layoutHashMacro(headerStr), other,
+		)
+	}
+	return nil
+}
+
+func (c *CGoStructGen) templateLayoutHashes(
+	f *os.File,
+	headerStr string,
+	g *headerGroup,
+) {
+	structNames := slices.Collect(maps.Keys(c.structs))
+	slices.Sort(structNames)
+	cntr := 0
+	for _, structName := range structNames {
+		if !g.has(structName) {
+			continue
+		}
+		fmt.Fprintf(
+			f, "\t#define %s 0x%016xULL\n",
+			layoutHashMacro(c.cTag(structName)), c.layoutHash(structName),
+		)
+		cntr++
+	}
+	if !g.isCommon() {
+		if cntr > 0 {
+			f.WriteString("\n")
+		}
+		return
+	}
+	fmt.Fprintf(
+		f, "\t#define %s 0x%016xULL\n\n",
+		layoutHashMacro(headerStr), c.headerLayoutHash(),
+	)
+}
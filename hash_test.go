@@ -0,0 +1,185 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestLayoutHashMissingStruct(t *testing.T) {
+	res := New(Opts{})
+	_, ok := res.LayoutHash("s1")
+	sbtest.False(t, ok)
+}
+
+func TestLayoutHashStable(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		f2 uint32
+	}
+	res1 := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res1))
+	res2 := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res2))
+
+	h1, ok := res1.LayoutHash("s1")
+	sbtest.True(t, ok)
+	h2, ok := res2.LayoutHash("s1")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, h1, h2)
+	sbtest.Eq(t, res1.HeaderLayoutHash(), res2.HeaderLayoutHash())
+}
+
+func TestLayoutHashFieldChange(t *testing.T) {
+	h1, hdr1 := func() (uint64, uint64) {
+		type s1 struct {
+			f1 int8
+			f2 uint32
+		}
+		res := New(Opts{})
+		sbtest.Nil(t, GenerateFor[s1](res))
+		h, _ := res.LayoutHash("s1")
+		return h, res.HeaderLayoutHash()
+	}()
+
+	type s1 struct {
+		f1 int8
+		f2 int32
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	h2, _ := res.LayoutHash("s1")
+	sbtest.Neq[uint64](t, h1, h2)
+	sbtest.Neq[uint64](t, hdr1, res.HeaderLayoutHash())
+}
+
+func TestLayoutHashNestedChange(t *testing.T) {
+	h1 := func() uint64 {
+		type s2 struct {
+			f1 int16
+			f2 int16
+		}
+		type s1 struct{ f1 s2 }
+		res := New(Opts{})
+		sbtest.Nil(t, GenerateFor[s1](res))
+		h, _ := res.LayoutHash("s1")
+		return h
+	}()
+
+	// Same size and field names, only the order inside the nested struct
+	// changed
+	type s2 struct {
+		f2 int16
+		f1 int16
+	}
+	type s1 struct{ f1 s2 }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	h2, _ := res.LayoutHash("s1")
+	sbtest.Neq[uint64](t, h1, h2)
+}
+
+func TestWriteLayoutHashes(t *testing.T) {
+	type s1 struct{ f1 int8 }
+	res := New(Opts{EmitLayoutHashes: true})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/layoutHashes.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	h, _ := res.LayoutHash("s1")
+	data, err := os.ReadFile("./bs/testData/layoutHashes.h")
+	sbtest.Nil(t, err)
+	exp := fmt.Sprintf(`#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t f1;
+	} s1_t;
+
+	#define S1_LAYOUT_HASH 0x%016xULL
+	#define HEADER_GUARD_LAYOUT_HASH 0x%016xULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`, h, res.HeaderLayoutHash())
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestWriteGoLayoutHashes(t *testing.T) {
+	type s1 struct{ f1 int8 }
+	type s2 struct {
+		f1 s1
+		f2 uint32
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	// Files starting with an underscore are ignored by the go tool
+	err := res.WriteGoLayoutHashesTo(
+		"./bs/testData/_layoutHashes.go", "sbcgostructgen", "HEADER_GUARD",
+	)
+	sbtest.Nil(t, err)
+
+	h1, _ := res.LayoutHash("s1")
+	h2, _ := res.LayoutHash("s2")
+	data, err := os.ReadFile("./bs/testData/_layoutHashes.go")
+	sbtest.Nil(t, err)
+	exp := fmt.Sprintf(`// Code generated by cgoStructGen - DO NOT EDIT.
+
+package sbcgostructgen
+
+// The layout hashes of the generated C structs and header.
+const (
+	S1_LAYOUT_HASH           uint64 = 0x%016x
+	S2_LAYOUT_HASH           uint64 = 0x%016x
+	HEADER_GUARD_LAYOUT_HASH uint64 = 0x%016x
+)
+`, h1, h2, res.HeaderLayoutHash())
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestLayoutHashMacroCollision(t *testing.T) {
+	type Pa struct{ f1 int8 }
+	type PA struct{ f1 int16 }
+	res := New(Opts{EmitLayoutHashes: true})
+	sbtest.Nil(t, GenerateFor[Pa](res))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[PA](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	sbtest.ContainsError(
+		t, DuplicateNameErr, GenerateConst(res, "PA_LAYOUT_HASH", int32(1)),
+	)
+	err := res.WriteTo("./bs/testData/layoutHashCollision.h", "PA")
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+
+	type s1 struct{ pa_layout_hash [2]int8 }
+	res = New(Opts{
+		EmitLayoutHashes:     true,
+		EmitArrayLenMacros:   true,
+		ArrayLenMacroPattern: "{FIELD}",
+	})
+	sbtest.Nil(t, GenerateFor[Pa](res))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+
+	// The Go constants are checked even if the macros are not written
+	res = New(Opts{})
+	sbtest.Nil(t, GenerateFor[Pa](res))
+	sbtest.Nil(t, GenerateFor[PA](res))
+	err = res.WriteGoLayoutHashesTo(
+		"./bs/testData/_layoutHashCollision.go", "sbcgostructgen", "HEADER",
+	)
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
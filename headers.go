@@ -0,0 +1,343 @@
+package sbcgostructgen
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// The parts of the generated code that are written to a single header
+	// when the output is split with [CGoStructGen.WriteHeadersTo].
+	headerGroup struct {
+		// The file name of the header
+		file string
+		// True if this is the header that holds everything that does not
+		// belong to a Go package, which every other header includes
+		common bool
+		// The names of the structs, typedefs, enums, and handles that are
+		// written to the header
+		members map[string]struct{}
+		// The file names of the other generated headers this header includes
+		includes []string
+	}
+)
+
+var (
+	IncludeCycleErr = errors.New("Include cycle")
+)
+
+// Returns true if the struct, typedef, enum, or handle with the supplied name
+// is written to the header. A nil group is a single header that holds
+// everything.
+func (g *headerGroup) has(name string) bool {
+	if g == nil {
+		return true
+	}
+	_, ok := g.members[name]
+	return ok
+}
+
+// Returns true if the header holds the parts of the generated code that do not
+// belong to a Go package, i.e. the includes, constants, and static asserts.
+func (g *headerGroup) isCommon() bool {
+	return g == nil || g.common
+}
+
+// Returns the include guard for the supplied header file name, i.e.
+// `COLOR_H` for `color.h`.
+func guardName(file string) string {
+	rv := strings.ToUpper(
+		nonIdentCharRegex.ReplaceAllString(filepath.Base(file), "_"),
+	)
+	if rv != "" && rv[0] >= '0' && rv[0] <= '9' {
+		rv = "H_" + rv
+	}
+	return rv
+}
+
+// Writes the struct definitions that were previously added through calls to
+// [GenerateFor] to multiple headers in the supplied directory, one per Go
+// package or per group of packages set with [Opts.HeaderGroups]. Each header
+// is named after its group, i.e. `color.h` for the `image/color` package, and
+// has an include guard derived from its file name.
+//
+// Everything that does not belong to a Go package, such as the includes,
+// constants, and static asserts, is written to the header with the supplied
+// common file name which every other header includes. Headers include the
+// other generated headers they depend on, an [IncludeCycleErr] is returned if
+// the groups depend on each other. If umbrella is not empty a header with that
+// file name is also written that includes every other header.
+//
+// Anonymous structs are written to the header of the struct that contains
+// them. Slice structs are written to the header of their element type, or to
+// the common header if the element type does not belong to a Go package. The
+// `<HEADER>_LAYOUT_HASH` define is written to the common header.
+func (c *CGoStructGen) WriteHeadersTo(
+	dir string,
+	common string,
+	umbrella string,
+) error {
+	var err error
+	var groups map[string]*headerGroup
+	var files []string
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+
+	if groups, err = c.headerGroups(common); err != nil {
+		goto errExit
+	}
+	files = slices.Collect(maps.Keys(groups))
+	slices.Sort(files)
+	for _, file := range files {
+		if err = c.writeHeader(
+			filepath.Join(dir, file), guardName(file), groups[file],
+		); err != nil {
+			goto errExit
+		}
+	}
+	if umbrella != "" {
+		if _, ok := groups[umbrella]; ok {
+			err = sberr.Wrap(
+				DuplicateNameErr,
+				"The umbrella header %s has the same name as a generated header",
+				umbrella,
+			)
+			goto errExit
+		}
+		err = c.writeUmbrellaHeader(filepath.Join(dir, umbrella), files)
+	}
+
+errExit:
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+// Returns the file name of the header the supplied Go package is written to.
+// Types that do not belong to a package are written to the common header.
+func (c *CGoStructGen) pkgHeader(pkgPath string, common string) string {
+	if pkgPath == "" {
+		return common
+	}
+	if group, ok := c.opts.HeaderGroups[pkgPath]; ok {
+		return group + ".h"
+	}
+	return path.Base(pkgPath) + ".h"
+}
+
+// Returns the file name of the header the struct with the supplied name is
+// written to, see [CGoStructGen.WriteHeadersTo].
+func (c *CGoStructGen) structHeader(structName string, common string) string {
+	refType, ok := c.types[structName]
+	if !ok {
+		return common
+	}
+	switch {
+	case refType.Kind() == reflect.Slice:
+		if data := c.structs[structName][0]; data.structRef != "" {
+			return c.structHeader(data.structRef, common)
+		}
+		elem := refType.Elem()
+		for elem.Kind() == reflect.Array || elem.Kind() == reflect.Pointer ||
+			elem.Kind() == reflect.Slice {
+			elem = elem.Elem()
+		}
+		return c.pkgHeader(elem.PkgPath(), common)
+	case refType.Name() == "":
+		return c.referrerHeader(structName, common)
+	}
+	return c.pkgHeader(refType.PkgPath(), common)
+}
+
+// Returns the file name of the header the first struct that refers to the
+// struct with the supplied name is written to. Slice structs are skipped over
+// so that anonymous slice elements are written with the struct that holds the
+// slice.
+func (c *CGoStructGen) referrerHeader(structName string, common string) string {
+	var refersTo func(fields []structField) bool
+	refersTo = func(fields []structField) bool {
+		return slices.ContainsFunc(fields, func(f structField) bool {
+			return f.structRef == structName || refersTo(f.inline)
+		})
+	}
+	for _, name := range c.sortedStructNames() {
+		if !refersTo(c.structs[name]) {
+			continue
+		}
+		if c.types[name].Kind() == reflect.Slice {
+			return c.referrerHeader(name, common)
+		}
+		return c.structHeader(name, common)
+	}
+	return common
+}
+
+// Splits the generated code into headers and determines which headers each
+// header includes.
+func (c *CGoStructGen) headerGroups(
+	common string,
+) (map[string]*headerGroup, error) {
+	rv := map[string]*headerGroup{
+		common: {file: common, common: true, members: map[string]struct{}{}},
+	}
+	// Maps the names used in field types to the header that defines them
+	cTypeHeaders := map[string]string{}
+	// Maps header file names to the package they were named after
+	pkgs := map[string]string{}
+	add := func(name string, cType string, file string, pkgPath string) error {
+		if pkgPath != "" && file == common {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The header for package %s has the same name as the common header",
+				pkgPath,
+			)
+		}
+		if _, ok := c.opts.HeaderGroups[pkgPath]; !ok && pkgPath != "" {
+			if other, ok := pkgs[file]; ok && other != pkgPath {
+				return sberr.Wrap(
+					DuplicateNameErr,
+					"The header %s is used by both %s and %s, add them to the header groups",
+					file, other, pkgPath,
+				)
+			}
+			pkgs[file] = pkgPath
+		}
+		if _, ok := rv[file]; !ok {
+			rv[file] = &headerGroup{file: file, members: map[string]struct{}{}}
+		}
+		rv[file].members[name] = struct{}{}
+		cTypeHeaders[cType] = file
+		return nil
+	}
+
+	for name, refType := range c.handles {
+		if err := add(
+			name, name, c.pkgHeader(refType.PkgPath(), common),
+			refType.PkgPath(),
+		); err != nil {
+			return nil, err
+		}
+	}
+	for name, refType := range c.typedefs {
+		if err := add(
+			name, c.cTypedef(name), c.pkgHeader(refType.PkgPath(), common),
+			refType.PkgPath(),
+		); err != nil {
+			return nil, err
+		}
+	}
+	for refType, enum := range c.enums {
+		if err := add(
+			enum.name, c.cTypedef(enum.name),
+			c.pkgHeader(refType.PkgPath(), common), refType.PkgPath(),
+		); err != nil {
+			return nil, err
+		}
+	}
+	for name, refType := range c.opaques {
+		if err := add(
+			name, c.cTypedef(name), c.pkgHeader(refType.PkgPath(), common),
+			refType.PkgPath(),
+		); err != nil {
+			return nil, err
+		}
+	}
+	for name := range c.structs {
+		file := c.structHeader(name, common)
+		pkgPath := ""
+		if refType := c.types[name]; refType.Kind() == reflect.Struct &&
+			refType.Name() != "" {
+			pkgPath = refType.PkgPath()
+		}
+		if err := add(name, c.cTypedef(name), file, pkgPath); err != nil {
+			return nil, err
+		}
+	}
+
+	var fieldIncludes func(g *headerGroup, fields []structField)
+	fieldIncludes = func(g *headerGroup, fields []structField) {
+		for _, iterField := range fields {
+			fieldIncludes(g, iterField.inline)
+			file, ok := cTypeHeaders[iterField._type]
+			if ok && file != g.file && !slices.Contains(g.includes, file) {
+				g.includes = append(g.includes, file)
+			}
+		}
+	}
+	for _, g := range rv {
+		if !g.common {
+			g.includes = append(g.includes, common)
+		}
+		for name := range g.members {
+			fieldIncludes(g, c.structs[name])
+		}
+		slices.Sort(g.includes)
+	}
+	if err := checkIncludeCycles(rv); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+// Returns an [IncludeCycleErr] if any of the supplied headers include each
+// other, either directly or through other headers.
+func checkIncludeCycles(groups map[string]*headerGroup) error {
+	files := slices.Collect(maps.Keys(groups))
+	slices.Sort(files)
+
+	done := map[string]struct{}{}
+	var visit func(file string, stack []string) error
+	visit = func(file string, stack []string) error {
+		if idx := slices.Index(stack, file); idx >= 0 {
+			return sberr.Wrap(
+				IncludeCycleErr, "The headers include each other: %s",
+				strings.Join(append(stack[idx:], file), " -> "),
+			)
+		}
+		if _, ok := done[file]; ok {
+			return nil
+		}
+		for _, inc := range groups[file].includes {
+			if err := visit(inc, append(stack, file)); err != nil {
+				return err
+			}
+		}
+		done[file] = struct{}{}
+		return nil
+	}
+	for _, file := range files {
+		if err := visit(file, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CGoStructGen) writeUmbrellaHeader(file string, headers []string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	c.templateHeader(f, guardName(file))
+	for _, h := range headers {
+		fmt.Fprintf(f, "#include %q\n", h)
+	}
+	f.WriteString("\n")
+	c.templateFooter(f)
+	return nil
+}
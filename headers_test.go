@@ -0,0 +1,288 @@
+package sbcgostructgen
+
+import (
+	goscanner "go/scanner"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type testHeaderShape struct {
+	Bounds  image.Rectangle
+	Fill    color.RGBA
+	Palette []color.RGBA
+	Points  []int32
+	Meta    struct{ Kind int8 }
+}
+
+func TestGuardName(t *testing.T) {
+	sbtest.Eq(t, "COLOR_H", guardName("color.h"))
+	sbtest.Eq(t, "SMOOTHBRAIN_CGOSTRUCTGEN_H", guardName("a/smoothbrain-cgostructgen.h"))
+	sbtest.Eq(t, "H_2D_H", guardName("2d.h"))
+}
+
+func TestHeaderGroups(t *testing.T) {
+	res := New(Opts{
+		IntMapping:       IntMappingPtrdiff,
+		SliceStructs:     true,
+		AnonymousStructs: AnonymousStructsNamed,
+	})
+	sbtest.Nil(t, GenerateFor[testHeaderShape](res))
+	groups, err := res.headerGroups("common.h")
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, 4, len(groups))
+
+	sbtest.True(t, groups["common.h"].common)
+	sbtest.MapsMatch(t,
+		map[string]struct{}{"Slice_int32": {}}, groups["common.h"].members,
+	)
+	sbtest.SlicesMatch(t, nil, groups["common.h"].includes)
+
+	sbtest.MapsMatch(t,
+		map[string]struct{}{"Point": {}, "Rectangle": {}},
+		groups["image.h"].members,
+	)
+	sbtest.SlicesMatch(t, []string{"common.h"}, groups["image.h"].includes)
+
+	sbtest.MapsMatch(t,
+		map[string]struct{}{"RGBA": {}, "Slice_RGBA": {}},
+		groups["color.h"].members,
+	)
+	sbtest.SlicesMatch(t, []string{"common.h"}, groups["color.h"].includes)
+
+	sbtest.MapsMatch(t,
+		map[string]struct{}{"testHeaderShape": {}, "testHeaderShape_Meta": {}},
+		groups["smoothbrain-cgostructgen.h"].members,
+	)
+	sbtest.SlicesMatch(t,
+		[]string{"color.h", "common.h", "image.h"},
+		groups["smoothbrain-cgostructgen.h"].includes,
+	)
+}
+
+func TestHeaderGroupsCustom(t *testing.T) {
+	res := New(Opts{
+		IntMapping:       IntMappingPtrdiff,
+		SliceStructs:     true,
+		AnonymousStructs: AnonymousStructsNamed,
+		HeaderGroups:     map[string]string{"image": "gfx", "image/color": "gfx"},
+	})
+	sbtest.Nil(t, GenerateFor[testHeaderShape](res))
+	groups, err := res.headerGroups("common.h")
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, 3, len(groups))
+	sbtest.Eq(t, 4, len(groups["gfx.h"].members))
+	sbtest.SlicesMatch(t, []string{"common.h"}, groups["gfx.h"].includes)
+	sbtest.SlicesMatch(t,
+		[]string{"common.h", "gfx.h"},
+		groups["smoothbrain-cgostructgen.h"].includes,
+	)
+}
+
+func TestHeaderGroupsDuplicateName(t *testing.T) {
+	type s1 struct{ F1 goscanner.Error }
+	res := New(Opts{IntMapping: IntMappingPtrdiff})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	_, err := res.headerGroups("common.h")
+	sbtest.Nil(t, err)
+
+	_, err = res.headerGroups("token.h")
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+
+	res.opts.HeaderGroups = map[string]string{"go/scanner": "common"}
+	_, err = res.headerGroups("common.h")
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
+
+func TestHeaderGroupsCycle(t *testing.T) {
+	type s1 struct {
+		F1 goscanner.Error
+		F2 image.Rectangle
+	}
+	type s2 struct{ F1 s1 }
+	res := New(Opts{IntMapping: IntMappingPtrdiff})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	_, err := res.headerGroups("common.h")
+	sbtest.Nil(t, err)
+
+	res.opts.HeaderGroups = map[string]string{
+		"go/token": "a",
+		"github.com/barbell-math/smoothbrain-cgostructgen": "a",
+	}
+	_, err = res.headerGroups("common.h")
+	sbtest.ContainsError(t, IncludeCycleErr, err)
+}
+
+func TestWriteHeadersTo(t *testing.T) {
+	dir := "./bs/testData/headers"
+	sbtest.Nil(t, os.MkdirAll(dir, 0755))
+	res := New(Opts{
+		IntMapping:       IntMappingPtrdiff,
+		SliceStructs:     true,
+		AnonymousStructs: AnonymousStructsNamed,
+		EmitLayoutHashes: true,
+	})
+	sbtest.Nil(t, GenerateFor[testHeaderShape](res))
+	sbtest.Nil(t, res.WriteHeadersTo(dir, "common.h", "all.h"))
+	sbtest.ContainsError(
+		t, DuplicateNameErr, res.WriteHeadersTo(dir, "common.h", "image.h"),
+	)
+
+	exp := map[string]string{
+		"all.h": `#ifndef ALL_H
+#define ALL_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "color.h"
+#include "common.h"
+#include "image.h"
+#include "smoothbrain-cgostructgen.h"
+
+#endif
+`,
+		"common.h": `#ifndef COMMON_H
+#define COMMON_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Slice_int32{
+		int32_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_int32_t;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+	#define SLICE_INT32_LAYOUT_HASH 0x4284b9d702daa4a5ULL
+	#define COMMON_H_LAYOUT_HASH 0xd936f6fda7bbe115ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`,
+		"image.h": `#ifndef IMAGE_H
+#define IMAGE_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "common.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Point{
+		ptrdiff_t X;
+		ptrdiff_t Y;
+	} Point_t;
+
+	typedef struct Rectangle{
+		Point_t Min;
+		Point_t Max;
+	} Rectangle_t;
+
+	#define POINT_LAYOUT_HASH 0x66c3fc2c5c151be9ULL
+	#define RECTANGLE_LAYOUT_HASH 0x0877c8079d86cbbcULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`,
+		"color.h": `#ifndef COLOR_H
+#define COLOR_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "common.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct RGBA{
+		uint8_t R;
+		uint8_t G;
+		uint8_t B;
+		uint8_t A;
+	} RGBA_t;
+
+	typedef struct Slice_RGBA{
+		RGBA_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_RGBA_t;
+
+	#define RGBA_LAYOUT_HASH 0x27a8b3ff20e16757ULL
+	#define SLICE_RGBA_LAYOUT_HASH 0xde938dc6000755f9ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`,
+		"smoothbrain-cgostructgen.h": `#ifndef SMOOTHBRAIN_CGOSTRUCTGEN_H
+#define SMOOTHBRAIN_CGOSTRUCTGEN_H
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include "color.h"
+#include "common.h"
+#include "image.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct testHeaderShape_Meta{
+		int8_t Kind;
+	} testHeaderShape_Meta_t;
+
+	typedef struct testHeaderShape{
+		Rectangle_t Bounds;
+		RGBA_t Fill;
+		Slice_RGBA_t Palette;
+		Slice_int32_t Points;
+		testHeaderShape_Meta_t Meta;
+	} testHeaderShape_t;
+
+	#define TESTHEADERSHAPE_LAYOUT_HASH 0x5018cb1440b2bc15ULL
+	#define TESTHEADERSHAPE_META_LAYOUT_HASH 0xc6f3fa6067fb5573ULL
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`,
+	}
+	for file, expData := range exp {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		sbtest.Nil(t, err)
+		sbtest.Eq(t, expData, string(data))
+	}
+}
@@ -0,0 +1,175 @@
+package sbcgostructgen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// Describes how identifiers that are not usable in C or C++ are handled.
+	// See [Opts.IdentifierEscaping].
+	IdentifierEscapeMode int
+)
+
+const (
+	// Identifiers that are not usable in C or C++ are rejected with an
+	// [InvalidIdentifierErr].
+	IdentifierEscapeError IdentifierEscapeMode = iota
+	// Identifiers that are not usable in C or C++ are changed so that they
+	// are:
+	//   - non-ASCII characters are replaced with `_u<code point>`, i.e.
+	//     `größe` becomes `gr_u00f6_u00dfe`
+	//   - runs of underscores are collapsed to a single underscore
+	//   - a leading underscore followed by a capital letter is prefixed with
+	//     `x`, i.e. `_Foo` becomes `x_Foo`
+	//   - keywords have [Opts.IdentifierEscapeSuffix] appended, i.e. `class`
+	//     becomes `class_`
+	IdentifierEscapeMangle
+)
+
+const defaultIdentifierEscapeSuffix = "_"
+
+var (
+	InvalidIdentifierErr = errors.New("Invalid identifier")
+
+	underscoreRunRegex = regexp.MustCompile(`__+`)
+
+	// Keywords of C11 and C++20 along with the macros defined by the standard
+	// headers this package includes. Maps the keyword to where it is from.
+	reservedWords = map[string]string{}
+)
+
+func init() {
+	for lang, words := range map[string][]string{
+		"C": {
+			"_Alignas", "_Alignof", "_Atomic", "_Bool", "_Complex",
+			"_Generic", "_Imaginary", "_Noreturn", "_Static_assert",
+			"_Thread_local", "restrict",
+		},
+		"C and C++": {
+			"auto", "break", "case", "char", "const", "continue", "default",
+			"do", "double", "else", "enum", "extern", "float", "for", "goto",
+			"if", "inline", "int", "long", "register", "return", "short",
+			"signed", "sizeof", "static", "struct", "switch", "typedef",
+			"union", "unsigned", "void", "volatile", "while",
+		},
+		"C++": {
+			"alignas", "alignof", "and", "and_eq", "asm", "bitand", "bitor",
+			"bool", "catch", "char8_t", "char16_t", "char32_t", "class",
+			"co_await", "co_return", "co_yield", "compl", "concept",
+			"const_cast", "consteval", "constexpr", "constinit", "decltype",
+			"delete", "dynamic_cast", "explicit", "export", "false", "friend",
+			"mutable", "namespace", "new", "noexcept", "not", "not_eq",
+			"nullptr", "operator", "or", "or_eq", "private", "protected",
+			"public", "reinterpret_cast", "requires", "static_assert",
+			"static_cast", "template", "this", "thread_local", "throw",
+			"true", "try", "typeid", "typename", "using", "virtual",
+			"wchar_t", "xor", "xor_eq",
+		},
+		"standard header macro": {
+			"NULL", "offsetof", "complex", "imaginary",
+		},
+	} {
+		for _, w := range words {
+			reservedWords[w] = lang
+		}
+	}
+}
+
+// Returns a description of why the supplied identifier cannot be used in C or
+// C++, or an empty string if it can be used.
+func identProblem(name string) string {
+	if lang, ok := reservedWords[name]; ok {
+		return fmt.Sprintf("'%s' is a %s keyword", name, lang)
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			return fmt.Sprintf("'%s' contains non-ASCII characters", name)
+		}
+	}
+	if !cIdentRegex.MatchString(name) {
+		return fmt.Sprintf("'%s' is not a valid C identifier", name)
+	}
+	if len(name) > 1 && name[0] == '_' && unicode.IsUpper(rune(name[1])) {
+		return fmt.Sprintf(
+			"'%s' is a reserved identifier, it starts with an underscore and a capital letter",
+			name,
+		)
+	}
+	if strings.Contains(name, "__") {
+		return fmt.Sprintf(
+			"'%s' is a reserved identifier, it contains a double underscore",
+			name,
+		)
+	}
+	return ""
+}
+
+// Returns an error if the supplied identifier cannot be used in C or C++.
+func checkIdent(name string) error {
+	if problem := identProblem(name); problem != "" {
+		return sberr.Wrap(InvalidIdentifierErr, "%s", problem)
+	}
+	return nil
+}
+
+// Returns an error if the supplied type name cannot be used in C or C++. Type
+// names are written as typedefs and, if tagged is true, as struct or enum tags.
+// See [NamingPolicy].
+func (c *CGoStructGen) checkTypeIdent(name string, tagged bool) error {
+	if tagged {
+		if err := checkIdent(c.cTag(name)); err != nil {
+			return err
+		}
+	}
+	return checkIdent(c.cTypedef(name))
+}
+
+// Returns the identifier that should be used in C for the supplied type name
+// according to [Opts.IdentifierEscaping]. The name is only mangled if it cannot
+// be used as described by [CGoStructGen.checkTypeIdent].
+func (c *CGoStructGen) cTypeIdent(name string, tagged bool) string {
+	if c.checkTypeIdent(name, tagged) == nil {
+		return name
+	}
+	return c.cIdent(name)
+}
+
+// Returns the identifier that should be used in C for the supplied name
+// according to [Opts.IdentifierEscaping]. Names are returned unchanged unless
+// they need to be mangled.
+func (c *CGoStructGen) cIdent(name string) string {
+	if c.opts.IdentifierEscaping != IdentifierEscapeMangle ||
+		identProblem(name) == "" {
+		return name
+	}
+
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r > 0xffff:
+			fmt.Fprintf(&sb, "_U%08x", r)
+		case r > unicode.MaxASCII:
+			fmt.Fprintf(&sb, "_u%04x", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	rv := underscoreRunRegex.ReplaceAllString(sb.String(), "_")
+	if len(rv) > 1 && rv[0] == '_' && unicode.IsUpper(rune(rv[1])) {
+		rv = "x" + rv
+	}
+	if _, ok := reservedWords[rv]; ok {
+		suffix := c.opts.IdentifierEscapeSuffix
+		if suffix == "" {
+			suffix = defaultIdentifierEscapeSuffix
+		}
+		rv += suffix
+	}
+	return rv
+}
@@ -0,0 +1,189 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestIdentProblem(t *testing.T) {
+	sbtest.Eq(t, "", identProblem("foo"))
+	sbtest.Eq(t, "", identProblem("_foo"))
+	sbtest.Eq(t, "", identProblem("Foo_Bar"))
+	sbtest.Eq(t, "'class' is a C++ keyword", identProblem("class"))
+	sbtest.Eq(t, "'int' is a C and C++ keyword", identProblem("int"))
+	sbtest.Eq(t, "'_Bool' is a C keyword", identProblem("_Bool"))
+	sbtest.Eq(t, "'NULL' is a standard header macro keyword", identProblem("NULL"))
+	sbtest.Eq(t,
+		"'größe' contains non-ASCII characters", identProblem("größe"),
+	)
+	sbtest.Eq(t,
+		"'_Foo' is a reserved identifier, it starts with an underscore and a capital letter",
+		identProblem("_Foo"),
+	)
+	sbtest.Eq(t,
+		"'a__b' is a reserved identifier, it contains a double underscore",
+		identProblem("a__b"),
+	)
+	sbtest.Eq(t, "'1a' is not a valid C identifier", identProblem("1a"))
+}
+
+func TestCIdent(t *testing.T) {
+	c := New(Opts{})
+	sbtest.Eq(t, "class", c.cIdent("class"))
+
+	c = New(Opts{IdentifierEscaping: IdentifierEscapeMangle})
+	sbtest.Eq(t, "foo", c.cIdent("foo"))
+	sbtest.Eq(t, "class_", c.cIdent("class"))
+	sbtest.Eq(t, "template_", c.cIdent("template"))
+	sbtest.Eq(t, "x_Foo", c.cIdent("_Foo"))
+	sbtest.Eq(t, "a_b", c.cIdent("a__b"))
+	sbtest.Eq(t, "gr_u00f6_u00dfe", c.cIdent("größe"))
+	sbtest.Eq(t, "x_U0001f600", c.cIdent("😀"))
+	sbtest.Eq(t, "_u00e4", c.cIdent("ä"))
+
+	c = New(Opts{
+		IdentifierEscaping:     IdentifierEscapeMangle,
+		IdentifierEscapeSuffix: "Field",
+	})
+	sbtest.Eq(t, "classField", c.cIdent("class"))
+	sbtest.Eq(t, "a_b", c.cIdent("a__b"))
+}
+
+func TestGenerateForReservedFieldNames(t *testing.T) {
+	type s1 struct{ class int32 }
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s1](New(Opts{})))
+
+	type s2 struct {
+		f1 int32 `cgo:"name=default"`
+	}
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s2](New(Opts{})))
+
+	type s3 struct{ größe int32 }
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s3](New(Opts{})))
+
+	type s4 struct {
+		f1 int32 `cgo:"name=_Foo"`
+	}
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s4](New(Opts{})))
+
+	type s5 struct{ a__b int32 }
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s5](New(Opts{})))
+
+	type new struct{ f1 int32 }
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[new](New(Opts{})))
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[new](New(Opts{
+		IdentifierEscaping: IdentifierEscapeMangle,
+	})))
+	res := New(Opts{
+		IdentifierEscaping:     IdentifierEscapeMangle,
+		IdentifierEscapeSuffix: "Type",
+	})
+	sbtest.Nil(t, GenerateFor[new](res))
+	_, ok := res.structs["newType"]
+	sbtest.True(t, ok)
+
+	type Foo_ struct{ f1 int32 }
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[Foo_](New(Opts{})))
+
+	type s6 struct{ f1 int32 }
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s6](New(Opts{
+		StructRename: map[string]string{"s6": "struct"},
+	})))
+}
+
+func TestGenerateForReservedFieldNamesCustomSuffix(t *testing.T) {
+	type s1 struct{ class int32 }
+	res := New(Opts{
+		IdentifierEscaping:     IdentifierEscapeMangle,
+		IdentifierEscapeSuffix: "__",
+	})
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s1](res))
+
+	res = New(Opts{
+		IdentifierEscaping:     IdentifierEscapeMangle,
+		IdentifierEscapeSuffix: "Field",
+	})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, "int32_t classField", res.structs["s1"][0].String())
+}
+
+func TestGenerateForReservedFieldNamesCollision(t *testing.T) {
+	type s1 struct {
+		class  int32
+		class_ int32
+	}
+	res := New(Opts{IdentifierEscaping: IdentifierEscapeMangle})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+
+	type s2 struct {
+		a__b int32
+		a_b  int32
+	}
+	res = New(Opts{IdentifierEscaping: IdentifierEscapeMangle})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s2](res))
+}
+
+func TestGenerateConstReservedName(t *testing.T) {
+	sbtest.ContainsError(
+		t, InvalidIdentifierErr, GenerateConst(New(Opts{}), "NULL", int32(0)),
+	)
+
+	res := New(Opts{IdentifierEscaping: IdentifierEscapeMangle})
+	sbtest.Nil(t, GenerateConst(res, "NULL", int32(0)))
+	_, ok := res.consts["NULL_"]
+	sbtest.True(t, ok)
+}
+
+func TestWriteToEscapedIdents(t *testing.T) {
+	type template int32
+	type escaped struct {
+		int      template
+		new      int32
+		class    float64
+		register uint8
+		größe    uint16
+		a__b     int16
+		f1       int64 `cgo:"name=_Foo"`
+	}
+	res := New(Opts{IdentifierEscaping: IdentifierEscapeMangle})
+	sbtest.Nil(t, GenerateFor[escaped](res))
+	err := res.WriteTo("./bs/testData/escapedIdents.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/escapedIdents.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <math.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef int32_t template_t;
+
+	typedef struct escaped{
+		template_t int_;
+		int32_t new_;
+		double_t class_;
+		uint8_t register_;
+		uint16_t gr_u00f6_u00dfe;
+		int16_t a_b;
+		int64_t x_Foo;
+	} escaped_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, exp, string(data))
+}
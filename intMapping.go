@@ -0,0 +1,100 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"unsafe"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// Describes how the platform width Go int and uint types are written in
+	// C. See [Opts.IntMapping].
+	IntMapping int
+
+	intCType struct {
+		name string
+		inc  include
+	}
+)
+
+const (
+	// Go ints and uints are rejected with an [UnderspecifiedTypeErr].
+	IntMappingNone IntMapping = iota
+	// Go ints are written as `ptrdiff_t` and uints as `size_t`.
+	IntMappingPtrdiff
+	// Go ints are written as `intptr_t` and uints as `uintptr_t`.
+	IntMappingIntptr
+	// Go ints are written as `GoInt` and uints as `GoUint`, which are
+	// typedef'd in the header in the same way cgo defines them.
+	IntMappingGoInt
+)
+
+var (
+	platformIntTypes = map[reflect.Kind]reflect.Type{
+		reflect.Int:  reflect.TypeFor[int](),
+		reflect.Uint: reflect.TypeFor[uint](),
+	}
+
+	intMappingCTypes = map[IntMapping]map[reflect.Kind]intCType{
+		IntMappingPtrdiff: {
+			reflect.Int:  {name: "ptrdiff_t", inc: "<stddef.h>"},
+			reflect.Uint: {name: "size_t", inc: "<stddef.h>"},
+		},
+		IntMappingIntptr: {
+			reflect.Int:  {name: "intptr_t", inc: "<stdint.h>"},
+			reflect.Uint: {name: "uintptr_t", inc: "<stdint.h>"},
+		},
+		IntMappingGoInt: {
+			reflect.Int:  {name: "GoInt"},
+			reflect.Uint: {name: "GoUint"},
+		},
+	}
+)
+
+// Returns the C type and include for the supplied platform width int kind
+// according to [Opts.IntMapping]. False is returned if the kind is not int or
+// uint or if ints are not mapped.
+func (c *CGoStructGen) intCType(kind reflect.Kind) (string, include, bool) {
+	cType, ok := intMappingCTypes[c.opts.IntMapping][kind]
+	if !ok {
+		return "", "", false
+	}
+	return cType.name, cType.inc, true
+}
+
+// Checks that the supplied platform width int kind can be written to C and
+// registers the C type so that its width is verified with static asserts in
+// the generated header.
+func (c *CGoStructGen) checkPlatformInt(kind reflect.Kind) error {
+	name, _, ok := c.intCType(kind)
+	if !ok {
+		return sberr.Wrap(
+			UnderspecifiedTypeErr, "Unknown int mapping %d", c.opts.IntMapping,
+		)
+	}
+	return c.checkExternalCType(platformIntTypes[kind], name, nil, true)
+}
+
+func (c *CGoStructGen) templateGoInts(f *os.File) {
+	if c.opts.IntMapping != IntMappingGoInt {
+		return
+	}
+	_, okInt := c.cTypes["GoInt"]
+	_, okUint := c.cTypes["GoUint"]
+	if !okInt && !okUint {
+		return
+	}
+
+	// Matches the definitions in the cgo export header so that both headers
+	// can be included together
+	intType, uintType := "int", "unsigned int"
+	if unsafe.Sizeof(int(0)) == 8 {
+		intType, uintType = "long long", "unsigned long long"
+	}
+	f.WriteString("#ifndef GO_CGO_PROLOGUE_H\n")
+	f.WriteString("\ttypedef " + intType + " GoInt;\n")
+	f.WriteString("\ttypedef " + uintType + " GoUint;\n")
+	f.WriteString("#endif\n\n")
+}
@@ -0,0 +1,117 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForIntMappingNone(t *testing.T) {
+	type s1 struct{ f1 int }
+	err := GenerateFor[s1](New(Opts{IntMapping: IntMappingNone}))
+	sbtest.ContainsError(t, UnderspecifiedTypeErr, err)
+
+	err = GenerateFor[s1](New(Opts{IntMapping: 100}))
+	sbtest.ContainsError(t, UnderspecifiedTypeErr, err)
+}
+
+func TestGenerateForIntMappingPtrdiff(t *testing.T) {
+	type s1 struct {
+		f1 int
+		f2 [2]uint
+		f3 *int
+	}
+	res := New(Opts{IntMapping: IntMappingPtrdiff})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "ptrdiff_t f1", fields[0].String())
+	sbtest.Eq(t, "size_t f2[2]", fields[1].String())
+	sbtest.Eq(t, "ptrdiff_t* f3", fields[2].String())
+	sbtest.MapsMatch(t,
+		map[include]struct{}{
+			"<stddef.h>": {}, "<assert.h>": {}, "<stdalign.h>": {},
+		},
+		res.includes,
+	)
+	sbtest.Eq(t, 4, len(res.staticAsserts()))
+}
+
+func TestGenerateForIntMappingIntptr(t *testing.T) {
+	type count uint
+	type s1 struct {
+		f1 int
+		f2 count
+	}
+	res := New(Opts{IntMapping: IntMappingIntptr})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "intptr_t f1", fields[0].String())
+	sbtest.Eq(t, "count_t f2", fields[1].String())
+	sbtest.Eq(t, 1, len(res.typedefs))
+}
+
+func TestGenerateForIntMappingKindOverride(t *testing.T) {
+	type s1 struct{ f1 int }
+	res := New(Opts{
+		IntMapping:    IntMappingIntptr,
+		KindOverrides: map[reflect.Kind]TypeOverride{reflect.Int: {Name: "int64_t"}},
+	})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, "int64_t f1", res.structs["s1"][0].String())
+	sbtest.Eq(t, 0, len(res.staticAsserts()))
+}
+
+func TestWriteIntMappingGoInt(t *testing.T) {
+	type count uint
+	type s1 struct {
+		f1 int
+		f2 count
+	}
+	res := New(Opts{IntMapping: IntMappingGoInt})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/goInt.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/goInt.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#ifndef GO_CGO_PROLOGUE_H
+	typedef long long GoInt;
+	typedef unsigned long long GoUint;
+#endif
+
+	typedef GoUint count_t;
+
+	typedef struct s1{
+		GoInt f1;
+		count_t f2;
+	} s1_t;
+
+	static_assert(sizeof(GoInt) == 8, "GoInt must have the same size as the Go type int");
+	static_assert(alignof(GoInt) == 8, "GoInt must have the same alignment as the Go type int");
+	static_assert(sizeof(GoUint) == 8, "GoUint must have the same size as the Go type uint");
+	static_assert(alignof(GoUint) == 8, "GoUint must have the same alignment as the Go type uint");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
@@ -0,0 +1,187 @@
+package sbcgostructgen
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// Describes how the words of a generated name are cased. Words are split
+	// on underscores and on changes from lower to upper case, i.e. `HTTPServer`
+	// and `http_server` both have the words `HTTP` and `Server`. Leading
+	// underscores are kept as is.
+	NameCase int
+
+	// Describes how the names of the generated C types and fields are formed.
+	// See [Opts.Naming].
+	NamingPolicy struct {
+		// The case of struct, typedef, enum, and handle names. The case is
+		// applied after [Opts.StructRename] and [Opts.TypedefRename], so
+		// renamed types are cased as well.
+		TypeCase NameCase
+		// The case of field names. Names given with the `name` option of the
+		// `cgo` tag are used as is.
+		FieldCase NameCase
+		// The pattern used to name struct and enum tags, `{Name}` is replaced
+		// with the cased type name. Defaults to `{Name}`.
+		TagPattern string
+		// The pattern used to name typedefs, `{Name}` is replaced with the
+		// cased type name. Defaults to `{Name}_t`. Handles are only named with
+		// this pattern if it is set, otherwise they keep their `Handle` suffix
+		// alone.
+		TypedefPattern string
+	}
+)
+
+const (
+	// Names are used as they are in Go.
+	NameCasePreserve NameCase = iota
+	// Words are lower case and separated with underscores, i.e. `http_server`.
+	NameCaseSnake
+	// Words are upper case and separated with underscores, i.e. `HTTP_SERVER`.
+	NameCaseScreamingSnake
+	// Words are capitalized and joined, except for the first word which is
+	// lower case, i.e. `httpServer`.
+	NameCaseCamel
+	// Words are capitalized and joined, i.e. `HttpServer`.
+	NameCasePascal
+)
+
+const (
+	defaultTagPattern     = "{Name}"
+	defaultTypedefPattern = "{Name}_t"
+)
+
+// Returns the words of the supplied name along with any leading underscores.
+func splitWords(name string) (string, []string) {
+	trimmed := strings.TrimLeft(name, "_")
+	lead := name[:len(name)-len(trimmed)]
+
+	words := []string{}
+	runes := []rune(trimmed)
+	start := 0
+	for i, r := range runes {
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+			(unicode.IsUpper(prev) && nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return lead, words
+}
+
+// Returns the supplied name converted to the supplied case.
+func convertCase(name string, nameCase NameCase) string {
+	if nameCase == NameCasePreserve {
+		return name
+	}
+	lead, words := splitWords(name)
+	for i, w := range words {
+		switch nameCase {
+		case NameCaseSnake:
+			words[i] = strings.ToLower(w)
+		case NameCaseScreamingSnake:
+			words[i] = strings.ToUpper(w)
+		case NameCaseCamel, NameCasePascal:
+			w = strings.ToLower(w)
+			if i > 0 || nameCase == NameCasePascal {
+				runes := []rune(w)
+				runes[0] = unicode.ToUpper(runes[0])
+				w = string(runes)
+			}
+			words[i] = w
+		}
+	}
+	switch nameCase {
+	case NameCaseSnake, NameCaseScreamingSnake:
+		return lead + strings.Join(words, "_")
+	default:
+		return lead + strings.Join(words, "")
+	}
+}
+
+// Returns the supplied type name in the case set by the [Opts.Naming] policy.
+func (c *CGoStructGen) typeCase(name string) string {
+	return convertCase(name, c.opts.Naming.TypeCase)
+}
+
+// Returns the supplied field name in the case set by the [Opts.Naming] policy.
+func (c *CGoStructGen) fieldCase(name string) string {
+	return convertCase(name, c.opts.Naming.FieldCase)
+}
+
+// Returns the tag of the struct or enum with the supplied name, i.e. the name
+// that follows the `struct` or `enum` keyword.
+func (c *CGoStructGen) cTag(name string) string {
+	pattern := c.opts.Naming.TagPattern
+	if pattern == "" {
+		pattern = defaultTagPattern
+	}
+	return strings.ReplaceAll(pattern, "{Name}", name)
+}
+
+// Returns the typedef name of the type with the supplied name.
+func (c *CGoStructGen) cTypedef(name string) string {
+	pattern := c.opts.Naming.TypedefPattern
+	if pattern == "" {
+		pattern = defaultTypedefPattern
+	}
+	return strings.ReplaceAll(pattern, "{Name}", name)
+}
+
+// Returns the supplied typedef name with the affixes of the typedef pattern
+// removed, if it has them.
+func (c *CGoStructGen) trimTypedefAffixes(typedef string) string {
+	pattern := c.opts.Naming.TypedefPattern
+	if pattern == "" {
+		pattern = defaultTypedefPattern
+	}
+	prefix, suffix, ok := strings.Cut(pattern, "{Name}")
+	if !ok || len(typedef) <= len(prefix)+len(suffix) ||
+		!strings.HasPrefix(typedef, prefix) ||
+		!strings.HasSuffix(typedef, suffix) {
+		return typedef
+	}
+	return typedef[len(prefix) : len(typedef)-len(suffix)]
+}
+
+// Registers the supplied C type names as belonging to the supplied Go type.
+// Struct and enum tags share a namespace in C, and in C++ they also share a
+// namespace with typedefs, so each name may only be used by a single Go type
+// once the naming policy has been applied.
+func (c *CGoStructGen) claimTypeNames(
+	refType reflect.Type,
+	names ...string,
+) error {
+	for _, name := range names {
+		if other, ok := c.typeNames[name]; ok && other != refType {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The C type name %s is used by both %s and %s",
+				name, other, refType,
+			)
+		}
+	}
+	for _, name := range names {
+		c.typeNames[name] = refType
+	}
+	return nil
+}
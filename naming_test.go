@@ -0,0 +1,218 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestConvertCase(t *testing.T) {
+	sbtest.Eq(t, "HTTPServer", convertCase("HTTPServer", NameCasePreserve))
+	sbtest.Eq(t, "http_server", convertCase("HTTPServer", NameCaseSnake))
+	sbtest.Eq(t, "http_server", convertCase("httpServer", NameCaseSnake))
+	sbtest.Eq(t, "http_server", convertCase("Http__Server", NameCaseSnake))
+	sbtest.Eq(t, "HTTP_SERVER", convertCase("HTTPServer", NameCaseScreamingSnake))
+	sbtest.Eq(t, "httpServer", convertCase("HTTPServer", NameCaseCamel))
+	sbtest.Eq(t, "httpServer", convertCase("http_server", NameCaseCamel))
+	sbtest.Eq(t, "HttpServer", convertCase("http_server", NameCasePascal))
+	sbtest.Eq(t, "vec3_d", convertCase("Vec3D", NameCaseSnake))
+	sbtest.Eq(t, "int32_val", convertCase("Int32Val", NameCaseSnake))
+	sbtest.Eq(t, "parent_limits", convertCase("Parent_Limits", NameCaseSnake))
+	sbtest.Eq(t, "_private_field", convertCase("_PrivateField", NameCaseSnake))
+	sbtest.Eq(t, "a", convertCase("A", NameCaseSnake))
+	sbtest.Eq(t, "", convertCase("", NameCaseSnake))
+}
+
+func TestNamingPolicyPatterns(t *testing.T) {
+	res := New(Opts{})
+	sbtest.Eq(t, "Foo", res.cTag("Foo"))
+	sbtest.Eq(t, "Foo_t", res.cTypedef("Foo"))
+	sbtest.Eq(t, "Foo", res.trimTypedefAffixes("Foo_t"))
+
+	res = New(Opts{Naming: NamingPolicy{
+		TagPattern:     "myproj_{Name}_s",
+		TypedefPattern: "myproj_{Name}",
+	}})
+	sbtest.Eq(t, "myproj_foo_s", res.cTag("foo"))
+	sbtest.Eq(t, "myproj_foo", res.cTypedef("foo"))
+	sbtest.Eq(t, "foo", res.trimTypedefAffixes("myproj_foo"))
+	sbtest.Eq(t, "int32_t", res.trimTypedefAffixes("int32_t"))
+}
+
+func TestGenerateForNamingPolicy(t *testing.T) {
+	type InnerVal struct{ SomeField int32 }
+	type OuterVal struct {
+		FirstVal  InnerVal
+		SecondVal *InnerVal
+		HTTPCode  uint16
+		Tagged    int8 `cgo:"name=KeepThis"`
+	}
+	res := New(Opts{Naming: NamingPolicy{
+		TypeCase:       NameCaseSnake,
+		FieldCase:      NameCaseSnake,
+		TagPattern:     "myproj_{Name}",
+		TypedefPattern: "myproj_{Name}",
+	}})
+	sbtest.Nil(t, GenerateFor[OuterVal](res))
+
+	sbtest.Eq(t, 2, len(res.structs))
+	fields := res.structs["outer_val"]
+	sbtest.Eq(t, "myproj_inner_val first_val", fields[0].String())
+	sbtest.Eq(t, "inner_val", fields[0].structRef)
+	sbtest.Eq(t, "myproj_inner_val* second_val", fields[1].String())
+	sbtest.Eq(t, "uint16_t http_code", fields[2].String())
+	sbtest.Eq(t, "int8_t KeepThis", fields[3].String())
+	sbtest.Eq(t, "int32_t some_field", res.structs["inner_val"][0].String())
+}
+
+func TestGenerateForNamingPolicyRename(t *testing.T) {
+	type s1 struct{ f1 int32 }
+	type MyInt int32
+	type s2 struct {
+		F1 s1
+		F2 MyInt
+	}
+	res := New(Opts{
+		StructRename:  map[string]string{"s1": "RenamedStruct"},
+		TypedefRename: map[string]string{"MyInt": "RenamedInt"},
+		Naming:        NamingPolicy{TypeCase: NameCaseScreamingSnake},
+	})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.Eq(t, "RENAMED_STRUCT_t F1", res.structs["S2"][0].String())
+	sbtest.Eq(t, "RENAMED_INT_t F2", res.structs["S2"][1].String())
+}
+
+func TestGenerateForNamingPolicyFieldCollision(t *testing.T) {
+	type s1 struct {
+		FooBar  int32
+		Foo_Bar int32
+	}
+	sbtest.Nil(t, GenerateFor[s1](New(Opts{})))
+	res := New(Opts{Naming: NamingPolicy{FieldCase: NameCaseSnake}})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+}
+
+func TestGenerateForNamingPolicyStructCollision(t *testing.T) {
+	type FooBar struct{ F1 int32 }
+	type Foo_Bar struct{ F1 int16 }
+	type s1 struct {
+		F1 FooBar
+		F2 Foo_Bar
+	}
+	sbtest.Nil(t, GenerateFor[s1](New(Opts{})))
+	res := New(Opts{Naming: NamingPolicy{TypeCase: NameCaseSnake}})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+}
+
+func TestGenerateForNamingPolicyKindCollision(t *testing.T) {
+	type Foo struct{ F1 int32 }
+	type FOO int32
+	type s1 struct {
+		F1 Foo
+		F2 FOO
+	}
+	sbtest.Nil(t, GenerateFor[s1](New(Opts{})))
+	res := New(Opts{Naming: NamingPolicy{
+		TypeCase:       NameCaseSnake,
+		TypedefPattern: "{Name}",
+	}})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+
+	type Bar int32
+	type s2 struct {
+		F1 Foo
+		F2 Bar
+	}
+	res = New(Opts{TypedefRename: map[string]string{"Bar": "Foo"}})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s2](res))
+}
+
+func TestGenerateForNamingPolicyKeyword(t *testing.T) {
+	type Class struct{ F1 int32 }
+	type s1 struct{ F1 Class }
+	sbtest.Nil(t, GenerateFor[s1](New(Opts{})))
+	res := New(Opts{Naming: NamingPolicy{
+		TypeCase:       NameCaseSnake,
+		TypedefPattern: "{Name}",
+	}})
+	sbtest.ContainsError(t, InvalidIdentifierErr, GenerateFor[s1](res))
+}
+
+func TestWriteToNamingPolicy(t *testing.T) {
+	type InnerVal struct {
+		SomeValues [2]int32
+	}
+	type OuterVal struct {
+		FirstVal  InnerVal
+		SecondVal *InnerVal
+		Color     testColor
+		Count     []uint8
+	}
+	res := New(Opts{
+		EmitArrayLenMacros: true,
+		SliceStructs:       true,
+		Naming: NamingPolicy{
+			TypeCase:       NameCaseSnake,
+			FieldCase:      NameCaseSnake,
+			TagPattern:     "myproj_{Name}",
+			TypedefPattern: "myproj_{Name}",
+		},
+	})
+	sbtest.Nil(t, GenerateEnum(res, testColorRed, testColorGreen))
+	sbtest.Nil(t, GenerateFor[OuterVal](res))
+	err := res.WriteTo("./bs/testData/namingPolicy.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/namingPolicy.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef int8_t myproj_test_color;
+	enum{
+		myproj_test_color_Red = 0,
+		myproj_test_color_Green = 1,
+	};
+
+	#define MYPROJ_INNER_VAL_SOME_VALUES_LEN 2
+	typedef struct myproj_inner_val{
+		int32_t some_values[MYPROJ_INNER_VAL_SOME_VALUES_LEN];
+	} myproj_inner_val;
+
+	typedef struct myproj_slice_uint8{
+		uint8_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} myproj_slice_uint8;
+
+	typedef struct myproj_outer_val{
+		myproj_inner_val first_val;
+		myproj_inner_val* second_val;
+		myproj_test_color color;
+		myproj_slice_uint8 count;
+	} myproj_outer_val;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, exp, string(data))
+}
@@ -0,0 +1,121 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+// Returns true if the supplied struct type is written as an opaque type, see
+// [Opts.OpaqueStructs].
+func (c *CGoStructGen) isOpaque(refType reflect.Type) bool {
+	if _, ok := c.opaqueTypes[refType]; ok {
+		return true
+	}
+	return slices.Contains(c.opts.OpaqueStructs, refType)
+}
+
+// Returns the type that is left once all arrays, pointers, and slices are
+// removed from the supplied type, along with true if any pointers or slices
+// were removed.
+func indirectElem(refType reflect.Type) (reflect.Type, bool) {
+	indirect := false
+	for {
+		switch refType.Kind() {
+		case reflect.Pointer, reflect.Slice:
+			indirect = true
+		case reflect.Array:
+		default:
+			return refType, indirect
+		}
+		refType = refType.Elem()
+	}
+}
+
+// Checks the `opaque` option of the `cgo` tag on the supplied field, marking
+// the struct type the field points to as opaque.
+func (c *CGoStructGen) checkOpaqueTag(
+	field reflect.StructField,
+	tag cgoTag,
+) error {
+	if !tag.opaque {
+		return nil
+	}
+	elem, indirect := indirectElem(field.Type)
+	if !indirect || elem.Kind() != reflect.Struct {
+		return sberr.Wrap(
+			InvalidTagErr,
+			"The opaque option can only be used on pointers to structs",
+		)
+	}
+	if tag._type != "" {
+		return sberr.Wrap(
+			InvalidTagErr,
+			"The type and opaque options cannot be used together",
+		)
+	}
+	c.opaqueTypes[elem] = struct{}{}
+	return nil
+}
+
+// Checks that the supplied opaque struct type can be forward declared and
+// registers it.
+func (c *CGoStructGen) checkOpaque(refType reflect.Type, fieldName string) error {
+	if refType.Name() == "" {
+		return sberr.Wrap(
+			AnonymousNameErr,
+			"Opaque structs must be named, field %s", fieldName,
+		)
+	}
+	name, _, err := c.cStructName(refType, "")
+	if err != nil {
+		return sberr.Wrap(err, "field %s", fieldName)
+	}
+	if other, ok := c.types[name]; ok && other != refType {
+		return sberr.Wrap(
+			DuplicateNameErr,
+			"The C struct %s is used by both %s and %s, field %s",
+			name, other, refType, fieldName,
+		)
+	}
+	if _, ok := c.structs[name]; ok {
+		return sberr.Wrap(
+			InvalidTypeErr,
+			"%s is opaque but was already used by value, field %s",
+			refType, fieldName,
+		)
+	}
+	if err := c.claimTypeNames(
+		refType, c.cTag(name), c.cTypedef(name),
+	); err != nil {
+		return sberr.Wrap(err, "field %s", fieldName)
+	}
+	if err := c.checkDocs(refType); err != nil {
+		return sberr.Wrap(err, "field %s", fieldName)
+	}
+	c.types[name] = refType
+	c.opaques[name] = refType
+	return nil
+}
+
+func (c *CGoStructGen) templateOpaques(f *os.File, g *headerGroup) {
+	names := slices.DeleteFunc(
+		slices.Collect(maps.Keys(c.opaques)),
+		func(name string) bool { return !g.has(name) },
+	)
+	if len(names) == 0 {
+		return
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		c.templateStructDoc(f, name)
+		fmt.Fprintf(
+			f, "\ttypedef struct %s %s;\n", c.cTag(name), c.cTypedef(name),
+		)
+	}
+	f.WriteString("\n")
+}
@@ -0,0 +1,193 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestIndirectElem(t *testing.T) {
+	type s1 struct{ f1 int32 }
+	elem, indirect := indirectElem(reflect.TypeFor[s1]())
+	sbtest.Eq(t, reflect.TypeFor[s1](), elem)
+	sbtest.False(t, indirect)
+
+	elem, indirect = indirectElem(reflect.TypeFor[[2]s1]())
+	sbtest.Eq(t, reflect.TypeFor[s1](), elem)
+	sbtest.False(t, indirect)
+
+	elem, indirect = indirectElem(reflect.TypeFor[[2]*[3]s1]())
+	sbtest.Eq(t, reflect.TypeFor[s1](), elem)
+	sbtest.True(t, indirect)
+
+	elem, indirect = indirectElem(reflect.TypeFor[[]s1]())
+	sbtest.Eq(t, reflect.TypeFor[s1](), elem)
+	sbtest.True(t, indirect)
+}
+
+func TestGenerateForOpaque(t *testing.T) {
+	type hidden struct {
+		// Fields of opaque structs are never checked
+		ch chan int
+	}
+	type s1 struct {
+		f1 *hidden
+		f2 [2]*hidden
+		f3 **hidden
+		f4 *[2]hidden
+	}
+	res := New(Opts{OpaqueStructs: []reflect.Type{reflect.TypeFor[hidden]()}})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	sbtest.Eq(t, 1, len(res.opaques))
+	sbtest.Eq(t, reflect.TypeFor[hidden](), res.opaques["hidden"])
+
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "hidden_t* f1", fields[0].String())
+	sbtest.Eq(t, "", fields[0].structRef)
+	sbtest.Eq(t, "hidden_t* f2[2]", fields[1].String())
+	sbtest.Eq(t, "hidden_t** f3", fields[2].String())
+	sbtest.Eq(t, "hidden_t* f4", fields[3].String())
+}
+
+func TestGenerateForOpaqueRoot(t *testing.T) {
+	type hidden struct{ ch chan int }
+	res := New(Opts{OpaqueStructs: []reflect.Type{reflect.TypeFor[hidden]()}})
+	sbtest.Nil(t, GenerateFor[hidden](res))
+	sbtest.Eq(t, 0, len(res.structs))
+	sbtest.Eq(t, 1, len(res.opaques))
+}
+
+func TestGenerateForOpaqueByValue(t *testing.T) {
+	type hidden struct{ f1 int32 }
+	opts := Opts{OpaqueStructs: []reflect.Type{reflect.TypeFor[hidden]()}}
+
+	type s1 struct{ f1 hidden }
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](New(opts)))
+
+	type s2 struct{ f1 [2]hidden }
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s2](New(opts)))
+
+	type s3 struct {
+		hidden `cgo:"embed=flatten"`
+	}
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s3](New(opts)))
+}
+
+func TestGenerateForOpaqueSlice(t *testing.T) {
+	type hidden struct{ f1 int32 }
+	type s1 struct{ f1 []hidden }
+	res := New(Opts{
+		IntMapping:    IntMappingPtrdiff,
+		SliceStructs:  true,
+		OpaqueStructs: []reflect.Type{reflect.TypeFor[hidden]()},
+	})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 2, len(res.structs))
+	sbtest.Eq(t, "hidden_t* data", res.structs["Slice_hidden"][0].String())
+}
+
+func TestGenerateForOpaqueTag(t *testing.T) {
+	type hidden struct{ f1 int32 }
+	type s1 struct {
+		f1 *hidden `cgo:"opaque=true"`
+		f2 *hidden
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	sbtest.Eq(t, "hidden_t* f2", res.structs["s1"][1].String())
+
+	type s2 struct{ f1 hidden }
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s2](res))
+
+	type s3 struct {
+		f1 hidden
+		f2 *hidden `cgo:"opaque=true"`
+	}
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s3](New(Opts{})))
+
+	type s4 struct {
+		f1 *hidden `cgo:"opaque=false"`
+	}
+	res = New(Opts{})
+	sbtest.Nil(t, GenerateFor[s4](res))
+	sbtest.Eq(t, 2, len(res.structs))
+}
+
+func TestGenerateForOpaqueTagInvalid(t *testing.T) {
+	type hidden struct{ f1 int32 }
+	type s1 struct {
+		f1 hidden `cgo:"opaque=true"`
+	}
+	sbtest.ContainsError(t, InvalidTagErr, GenerateFor[s1](New(Opts{})))
+
+	type s2 struct {
+		f1 *int32 `cgo:"opaque=true"`
+	}
+	sbtest.ContainsError(t, InvalidTagErr, GenerateFor[s2](New(Opts{})))
+
+	type s3 struct {
+		f1 *hidden `cgo:"opaque=maybe"`
+	}
+	sbtest.ContainsError(t, InvalidTagErr, GenerateFor[s3](New(Opts{})))
+
+	type s4 struct {
+		f1 *hidden `cgo:"opaque=true,type=void*"`
+	}
+	sbtest.ContainsError(t, InvalidTagErr, GenerateFor[s4](New(Opts{})))
+
+	type s5 struct {
+		f1 *struct{ f1 int32 } `cgo:"opaque=true"`
+	}
+	sbtest.ContainsError(t, AnonymousNameErr, GenerateFor[s5](New(Opts{})))
+}
+
+func TestWriteToOpaque(t *testing.T) {
+	type Engine struct{ ch chan int }
+	type Config struct{ Threads int32 }
+	type Session struct {
+		Engine  *Engine
+		Config  *Config `cgo:"opaque=true"`
+		Workers [4]*Engine
+		ID      uint64
+	}
+	res := New(Opts{OpaqueStructs: []reflect.Type{reflect.TypeFor[Engine]()}})
+	sbtest.Nil(t, GenerateFor[Session](res))
+	err := res.WriteTo("./bs/testData/opaque.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/opaque.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Config Config_t;
+	typedef struct Engine Engine_t;
+
+	typedef struct Session{
+		Engine_t* Engine;
+		Config_t* Config;
+		Engine_t* Workers[4];
+		uint64_t ID;
+	} Session_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, exp, string(data))
+}
@@ -0,0 +1,128 @@
+package sbcgostructgen
+
+import (
+	"errors"
+	"reflect"
+	"unsafe"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// A C type that is used in place of the C type that would otherwise be
+	// generated for a Go kind or type. See [Opts.KindOverrides] and
+	// [Opts.TypeOverrides].
+	TypeOverride struct {
+		// The name of the C type, i.e. `double` or `unsigned char`.
+		Name string
+		// The include that provides the C type, if any. It must be surrounded
+		// with either angle brackets or quotes, i.e. `<stddef.h>`.
+		Include string
+	}
+)
+
+var (
+	LayoutMismatchErr = errors.New("Layout mismatch")
+
+	// The sizes of C types that are known to have a fixed size for the target
+	// platform. Overrides that use any other C type are verified with static
+	// asserts in the generated header.
+	knownCTypeSizes = map[string]uintptr{
+		"char":          1,
+		"signed char":   1,
+		"unsigned char": 1,
+		"bool":          1,
+		"_Bool":         1,
+		"int8_t":        1,
+		"uint8_t":       1,
+		"int16_t":       2,
+		"uint16_t":      2,
+		"int32_t":       4,
+		"uint32_t":      4,
+		"int64_t":       8,
+		"uint64_t":      8,
+		"float":         4,
+		"double":        8,
+		"void*":         unsafe.Sizeof(uintptr(0)),
+		"char*":         unsafe.Sizeof(uintptr(0)),
+		"intptr_t":      unsafe.Sizeof(uintptr(0)),
+		"uintptr_t":     unsafe.Sizeof(uintptr(0)),
+		"ptrdiff_t":     unsafe.Sizeof(uintptr(0)),
+		"size_t":        unsafe.Sizeof(uintptr(0)),
+	}
+
+	// Kinds that describe the structure of a type rather than a value, these
+	// cannot be overridden by kind.
+	nonOverridableKinds = map[reflect.Kind]struct{}{
+		reflect.Array:   {},
+		reflect.Pointer: {},
+		reflect.Struct:  {},
+	}
+)
+
+// Returns the C type and include for the supplied kind, taking any
+// [Opts.KindOverrides] into account. False is returned if the kind has no C
+// representation.
+func (c *CGoStructGen) kindCType(kind reflect.Kind) (string, include, bool) {
+	if o, ok := c.opts.KindOverrides[kind]; ok {
+		return o.Name, include(o.Include), true
+	}
+	if name, i, ok := c.intCType(kind); ok {
+		return name, i, true
+	}
+	if name, i, ok := c.complexCType(kind); ok {
+		return name, i, true
+	}
+	if e, ok := reflectToEnumTypes[kind]; ok {
+		return e.String(), reflectToIncludes[kind], true
+	}
+	return "", "", false
+}
+
+// Checks that the [Opts.KindOverrides] do not contain any kinds that cannot be
+// overridden.
+func (c *CGoStructGen) checkKindOverrides() error {
+	for kind := range c.opts.KindOverrides {
+		if _, ok := nonOverridableKinds[kind]; ok {
+			return sberr.Wrap(
+				InvalidTypeErr, "The %s kind cannot be overridden", kind,
+			)
+		}
+		if _, dims := splitCTypeDims(c.opts.KindOverrides[kind].Name); len(dims) > 0 {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"The %s kind cannot be overridden with the array type %s",
+				kind, c.opts.KindOverrides[kind].Name,
+			)
+		}
+	}
+	return nil
+}
+
+// Checks that the supplied override is valid for the supplied type. If the
+// size of the C type is known it is checked against the size of the Go type,
+// otherwise the C type is registered so that its layout is verified with
+// static asserts in the generated header.
+func (c *CGoStructGen) checkTypeOverride(
+	refType reflect.Type,
+	o TypeOverride,
+) error {
+	includes := []string{}
+	if o.Include != "" {
+		includes = append(includes, o.Include)
+	}
+	if size, ok := knownCTypeSizes[o.Name]; ok {
+		if err := c.checkExternalCType(refType, o.Name, includes, false); err != nil {
+			return err
+		}
+		if size != refType.Size() {
+			return sberr.Wrap(
+				LayoutMismatchErr,
+				"The C type %s is %d bytes but the Go type %s is %d bytes",
+				o.Name, size, refType, refType.Size(),
+			)
+		}
+		return nil
+	}
+	return c.checkExternalCType(refType, o.Name, includes, true)
+}
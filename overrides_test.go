@@ -0,0 +1,184 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForKindOverrides(t *testing.T) {
+	type s1 struct {
+		f1 float32
+		f2 float64
+		f3 uintptr
+		f4 uint8
+		f5 [2]float64
+	}
+	res := New(Opts{KindOverrides: map[reflect.Kind]TypeOverride{
+		reflect.Float32: {Name: "float"},
+		reflect.Float64: {Name: "double"},
+		reflect.Uintptr: {Name: "uintptr_t", Include: "<stdint.h>"},
+		reflect.Uint8:   {Name: "unsigned char"},
+	}})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "float f1", fields[0].String())
+	sbtest.Eq(t, "double f2", fields[1].String())
+	sbtest.Eq(t, "uintptr_t f3", fields[2].String())
+	sbtest.Eq(t, "unsigned char f4", fields[3].String())
+	sbtest.Eq(t, "double f5[2]", fields[4].String())
+	sbtest.MapsMatch(t, map[include]struct{}{"<stdint.h>": {}}, res.includes)
+	sbtest.Eq(t, 0, len(res.staticAsserts()))
+}
+
+func TestGenerateForKindOverrideSizeMismatch(t *testing.T) {
+	type s1 struct{ f1 float32 }
+	res := New(Opts{KindOverrides: map[reflect.Kind]TypeOverride{
+		reflect.Float32: {Name: "double"},
+	}})
+	sbtest.ContainsError(t, LayoutMismatchErr, GenerateFor[s1](res))
+}
+
+func TestGenerateForKindOverrideInvalid(t *testing.T) {
+	type s1 struct{ f1 float32 }
+	res := New(Opts{KindOverrides: map[reflect.Kind]TypeOverride{
+		reflect.Struct: {Name: "double"},
+	}})
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](res))
+
+	res = New(Opts{KindOverrides: map[reflect.Kind]TypeOverride{
+		reflect.Float32: {Name: "float", Include: "math.h"},
+	}})
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](res))
+}
+
+func TestGenerateForKindOverrideUnsupportedKind(t *testing.T) {
+	type s1 struct{ f1 int }
+	res := New(Opts{KindOverrides: map[reflect.Kind]TypeOverride{
+		reflect.Int: {Name: "int64_t", Include: "<stdint.h>"},
+	}})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, "int64_t f1", res.structs["s1"][0].String())
+}
+
+func TestGenerateForKindOverrideArray(t *testing.T) {
+	type s1 struct{ f1 int64 }
+	res := New(Opts{KindOverrides: map[reflect.Kind]TypeOverride{
+		reflect.Int64: {Name: "uint8_t[8]"},
+	}})
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](res))
+}
+
+func TestGenerateForTypeOverrideArray(t *testing.T) {
+	type id [4]uint32
+	type s1 struct {
+		f1 id
+		f2 [2]id
+	}
+	res := New(Opts{TypeOverrides: map[reflect.Type]TypeOverride{
+		reflect.TypeFor[id](): {Name: "uint32_t[4]"},
+	}})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "uint32_t f1[4]", fields[0].String())
+	sbtest.Eq(t, "uint32_t f2[2][4]", fields[1].String())
+}
+
+func TestGenerateForTypeOverrides(t *testing.T) {
+	type meters float64
+	type s2 struct{ f1 int32 }
+	type s1 struct {
+		f1 meters
+		f2 float64
+		f3 s2
+		f4 *s2
+	}
+	res := New(Opts{TypeOverrides: map[reflect.Type]TypeOverride{
+		reflect.TypeFor[meters](): {Name: "double"},
+		reflect.TypeFor[s2]():     {Name: "struct ext", Include: `"ext.h"`},
+	}})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	sbtest.Eq(t, 0, len(res.typedefs))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "double f1", fields[0].String())
+	sbtest.Eq(t, "double_t f2", fields[1].String())
+	sbtest.Eq(t, "struct ext f3", fields[2].String())
+	sbtest.Eq(t, "struct ext* f4", fields[3].String())
+	sbtest.SlicesMatch(t,
+		[]staticAssert{
+			{
+				cond: "sizeof(struct ext) == 4",
+				msg:  "struct ext must have the same size as the Go type sbcgostructgen.s2",
+			},
+			{
+				cond: "alignof(struct ext) == 4",
+				msg:  "struct ext must have the same alignment as the Go type sbcgostructgen.s2",
+			},
+		},
+		res.staticAsserts(),
+	)
+}
+
+func TestWriteKindOverrides(t *testing.T) {
+	type meters float64
+	type s1 struct {
+		f1 meters
+		f2 float32
+		f3 testColor
+	}
+	res := New(Opts{
+		KindOverrides: map[reflect.Kind]TypeOverride{
+			reflect.Float32: {Name: "float"},
+			reflect.Float64: {Name: "double"},
+			reflect.Int8:    {Name: "signed char"},
+		},
+		StaticConsts: true,
+	})
+	err := GenerateConst(res, "SCALE", float64(0.5))
+	sbtest.Nil(t, err)
+	err = GenerateEnum(res, testColorRed)
+	sbtest.Nil(t, err)
+	err = GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/kindOverrides.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/kindOverrides.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	static const double SCALE = 0.5;
+
+	typedef double meters_t;
+
+	typedef signed char testColor_t;
+	enum testColor{
+		testColor_Red = 0,
+	};
+
+	typedef struct s1{
+		meters_t f1;
+		float f2;
+		testColor_t f3;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
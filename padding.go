@@ -0,0 +1,153 @@
+package sbcgostructgen
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+type (
+	// The padding information for a single field in a generated C struct.
+	FieldPadding struct {
+		Name   string
+		Offset uintptr
+		Size   uintptr
+		Align  uintptr
+		// The number of padding bytes between the end of this field and the
+		// start of the next field, or the end of the struct if this is the
+		// last field.
+		PaddingAfter uintptr
+	}
+
+	// The padding information for a single generated C struct.
+	StructPadding struct {
+		Name   string
+		Size   uintptr
+		Align  uintptr
+		Fields []FieldPadding
+		// The total number of padding bytes in the struct, including any
+		// trailing padding.
+		Padding uintptr
+		// The proposed field order. If no order with less padding could be
+		// found this will be the current field order.
+		SuggestedOrder []string
+		// The size the struct would have if the fields were arranged in the
+		// suggested order.
+		SuggestedSize uintptr
+		// The number of padding bytes the struct would have if the fields were
+		// arranged in the suggested order.
+		SuggestedPadding uintptr
+	}
+)
+
+// Reports the padding in every struct that was previously added through calls
+// to [GenerateFor], sorted by C struct name. For each struct a field order that
+// minimizes padding is also proposed. The proposed order places fields with
+// larger alignments first, which for the fixed size types supported by this
+// package results in the minimal amount of padding. Reserved fields that only
+// fill padding, such as those written for blank and dropped zero size fields,
+// are counted as padding and are not part of the report or suggested order.
+func (c *CGoStructGen) PaddingReport() []StructPadding {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+
+	structNames := slices.Collect(maps.Keys(c.structs))
+	slices.Sort(structNames)
+
+	rv := make([]StructPadding, 0, len(structNames))
+	for _, structName := range structNames {
+		rv = append(rv, c.structPadding(structName))
+	}
+	return rv
+}
+
+func (c *CGoStructGen) structPadding(structName string) StructPadding {
+	structFields := slices.DeleteFunc(
+		slices.Clone(c.structs[structName]),
+		func(f structField) bool { return f.reserved },
+	)
+	rv := StructPadding{
+		Name:   structName,
+		Fields: make([]FieldPadding, len(structFields)),
+	}
+	if refType, ok := c.types[structName]; ok {
+		rv.Size = refType.Size()
+		rv.Align = uintptr(refType.Align())
+	}
+
+	var used uintptr
+	for i, iterField := range structFields {
+		end := rv.Size
+		if i+1 < len(structFields) {
+			end = structFields[i+1].offset
+		}
+		rv.Fields[i] = FieldPadding{
+			Name:         iterField.name,
+			Offset:       iterField.offset,
+			Size:         iterField.size,
+			Align:        iterField.align,
+			PaddingAfter: end - iterField.offset - iterField.size,
+		}
+		used += iterField.size
+	}
+	rv.Padding = rv.Size - used
+
+	suggested := slices.Clone(rv.Fields)
+	slices.SortStableFunc(suggested, func(l FieldPadding, r FieldPadding) int {
+		if res := cmp.Compare(r.Align, l.Align); res != 0 {
+			return res
+		}
+		return cmp.Compare(r.Size, l.Size)
+	})
+	var offset uintptr
+	for _, iterField := range suggested {
+		offset = alignUp(offset, iterField.Align) + iterField.Size
+	}
+	rv.SuggestedSize = alignUp(offset, rv.Align)
+
+	if rv.SuggestedSize < rv.Size {
+		rv.SuggestedPadding = rv.SuggestedSize - used
+	} else {
+		suggested = rv.Fields
+		rv.SuggestedSize = rv.Size
+		rv.SuggestedPadding = rv.Padding
+	}
+	rv.SuggestedOrder = make([]string, len(suggested))
+	for i, iterField := range suggested {
+		rv.SuggestedOrder[i] = iterField.Name
+	}
+	return rv
+}
+
+func alignUp(v uintptr, align uintptr) uintptr {
+	if align == 0 {
+		return v
+	}
+	return (v + align - 1) / align * align
+}
+
+// Writes a table to the supplied writer that shows the current and suggested
+// size and padding of every struct that was previously added through calls to
+// [GenerateFor]. See [CGoStructGen.PaddingReport] for how the suggested field
+// order is determined.
+func (c *CGoStructGen) WritePaddingReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(
+		tw,
+		"Struct\tSize\tPadding\tSuggested Size\tSuggested Padding\tSuggested Order",
+	)
+	for _, iterStruct := range c.PaddingReport() {
+		fmt.Fprintf(
+			tw, "%s\t%d\t%d\t%d\t%d\t%s\n",
+			iterStruct.Name, iterStruct.Size, iterStruct.Padding,
+			iterStruct.SuggestedSize, iterStruct.SuggestedPadding,
+			strings.Join(iterStruct.SuggestedOrder, ", "),
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,141 @@
+package sbcgostructgen
+
+import (
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestPaddingReportNoPadding(t *testing.T) {
+	type s1 struct {
+		f1 int32
+		f2 int16
+		f3 int8
+		f4 int8
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	report := res.PaddingReport()
+	sbtest.Eq(t, 1, len(report))
+	sbtest.Eq(t, "s1", report[0].Name)
+	sbtest.Eq(t, 8, report[0].Size)
+	sbtest.Eq(t, 0, report[0].Padding)
+	sbtest.Eq(t, 8, report[0].SuggestedSize)
+	sbtest.Eq(t, 0, report[0].SuggestedPadding)
+	sbtest.SlicesMatch(t,
+		[]string{"f1", "f2", "f3", "f4"}, report[0].SuggestedOrder,
+	)
+}
+
+func TestPaddingReportWithPadding(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		f2 int64
+		f3 int16
+		f4 [3]int8
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	report := res.PaddingReport()
+	sbtest.Eq(t, 1, len(report))
+	sbtest.Eq(t, 24, report[0].Size)
+	sbtest.Eq(t, 8, report[0].Align)
+	sbtest.Eq(t, 10, report[0].Padding)
+	sbtest.SlicesMatch(t,
+		[]FieldPadding{
+			{Name: "f1", Offset: 0, Size: 1, Align: 1, PaddingAfter: 7},
+			{Name: "f2", Offset: 8, Size: 8, Align: 8, PaddingAfter: 0},
+			{Name: "f3", Offset: 16, Size: 2, Align: 2, PaddingAfter: 0},
+			{Name: "f4", Offset: 18, Size: 3, Align: 1, PaddingAfter: 3},
+		},
+		report[0].Fields,
+	)
+	sbtest.Eq(t, 16, report[0].SuggestedSize)
+	sbtest.Eq(t, 2, report[0].SuggestedPadding)
+	sbtest.SlicesMatch(t,
+		[]string{"f2", "f3", "f4", "f1"}, report[0].SuggestedOrder,
+	)
+}
+
+func TestPaddingReportNestedStruct(t *testing.T) {
+	type s2 struct {
+		f1 int32
+		f2 int8
+	}
+	type s1 struct {
+		f1 int8
+		f2 s2
+		f3 int8
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	report := res.PaddingReport()
+	sbtest.Eq(t, 2, len(report))
+	sbtest.Eq(t, "s1", report[0].Name)
+	sbtest.Eq(t, 16, report[0].Size)
+	sbtest.Eq(t, 12, report[0].SuggestedSize)
+	sbtest.SlicesMatch(t,
+		[]string{"f2", "f1", "f3"}, report[0].SuggestedOrder,
+	)
+	sbtest.Eq(t, "s2", report[1].Name)
+	sbtest.Eq(t, 8, report[1].Size)
+	sbtest.Eq(t, 3, report[1].Padding)
+	sbtest.Eq(t, 8, report[1].SuggestedSize)
+}
+
+func TestPaddingReportReservedFields(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		_  [3]int8
+		f2 int32
+		f3 [0]int64
+		f4 int8
+		f5 int64
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, "uint8_t _reserved_1[3]", res.structs["s1"][1].String())
+
+	report := res.PaddingReport()
+	sbtest.Eq(t, 1, len(report))
+	sbtest.Eq(t, 24, report[0].Size)
+	sbtest.Eq(t, 10, report[0].Padding)
+	sbtest.SlicesMatch(t,
+		[]FieldPadding{
+			{Name: "f1", Offset: 0, Size: 1, Align: 1, PaddingAfter: 3},
+			{Name: "f2", Offset: 4, Size: 4, Align: 4, PaddingAfter: 0},
+			{Name: "f4", Offset: 8, Size: 1, Align: 1, PaddingAfter: 7},
+			{Name: "f5", Offset: 16, Size: 8, Align: 8, PaddingAfter: 0},
+		},
+		report[0].Fields,
+	)
+	sbtest.Eq(t, 16, report[0].SuggestedSize)
+	sbtest.Eq(t, 2, report[0].SuggestedPadding)
+	sbtest.SlicesMatch(t,
+		[]string{"f5", "f2", "f1", "f4"}, report[0].SuggestedOrder,
+	)
+}
+
+func TestWritePaddingReport(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		f2 int64
+		f3 int8
+	}
+	type s2 struct{ f1 int32 }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Nil(t, GenerateFor[s2](res))
+
+	var sb strings.Builder
+	sbtest.Nil(t, res.WritePaddingReport(&sb))
+	exp := `Struct  Size  Padding  Suggested Size  Suggested Padding  Suggested Order
+s1      24    14       16              6                  f2, f1, f3
+s2      4     0        4               0                  f1
+`
+	sbtest.Eq(t, exp, sb.String())
+}
@@ -0,0 +1,143 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+type (
+	// A single field path through which a Go pointer can reach C.
+	PointerRuleViolation struct {
+		// The path to the offending field from the root of the struct, i.e.
+		// `f1.f2[].f3`. Array elements are denoted with `[]`.
+		Path string
+		// The kind of the offending field.
+		Kind reflect.Kind
+		// A suggested alternative that does not violate the cgo pointer
+		// passing rules.
+		Suggestion string
+	}
+
+	// The result of checking a single struct against the cgo pointer passing
+	// rules.
+	PointerRuleReport struct {
+		// The C name of the struct.
+		Name string
+		// True if a pointer to the struct can be passed to C without violating
+		// the cgo pointer passing rules, meaning the struct does not contain
+		// any Go pointers.
+		SafeToPassByPointer bool
+		Violations          []PointerRuleViolation
+	}
+)
+
+var pointerRuleSuggestions = map[reflect.Kind]string{
+	reflect.String:        "replace with a fixed size byte array, or pass a C owned string from C.CString",
+	reflect.Pointer:       "pin the pointed to value with runtime.Pinner for the duration of the C call, or pass a runtime/cgo.Handle instead",
+	reflect.UnsafePointer: "pin the pointed to value with runtime.Pinner for the duration of the C call, or pass a runtime/cgo.Handle instead",
+	reflect.Slice:         "pin the backing array with runtime.Pinner for the duration of the C call, or copy the data into C owned memory",
+	reflect.Map:           "pass a runtime/cgo.Handle instead",
+	reflect.Chan:          "pass a runtime/cgo.Handle instead",
+	reflect.Func:          "pass a runtime/cgo.Handle instead",
+	reflect.Interface:     "pass a runtime/cgo.Handle instead",
+}
+
+// Checks every struct that was previously added through calls to
+// [GenerateFor] against the cgo pointer passing rules, returning a report per
+// struct sorted by C struct name. The cgo rules state that Go memory passed to
+// C must not contain any Go pointers, so every field path through which a Go
+// pointer could reach C is reported. This includes strings, pointers, and
+// unsafe pointers along with any of those kinds nested inside arrays or
+// structs. Pointers are not followed, the pointer itself is the violation.
+//
+// Uintptrs are not reported as the garbage collector does not treat them as
+// pointers.
+func (c *CGoStructGen) PointerRuleReport() []PointerRuleReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
+
+	structNames := slices.Collect(maps.Keys(c.structs))
+	slices.Sort(structNames)
+
+	rv := make([]PointerRuleReport, 0, len(structNames))
+	for _, structName := range structNames {
+		report := PointerRuleReport{Name: structName}
+		if refType, ok := c.types[structName]; ok {
+			path := ""
+			if refType.Kind() == reflect.Slice {
+				// Slice structs represent the slice header itself, the
+				// pointer to the backing array is held in the data field
+				path = "data"
+			}
+			report.Violations = pointerRuleViolations(refType, path, nil)
+		}
+		report.SafeToPassByPointer = len(report.Violations) == 0
+		rv = append(rv, report)
+	}
+	return rv
+}
+
+func pointerRuleViolations(
+	refType reflect.Type,
+	path string,
+	violations []PointerRuleViolation,
+) []PointerRuleViolation {
+	if suggestion, ok := pointerRuleSuggestions[refType.Kind()]; ok {
+		return append(violations, PointerRuleViolation{
+			Path:       path,
+			Kind:       refType.Kind(),
+			Suggestion: suggestion,
+		})
+	}
+
+	switch refType.Kind() {
+	case reflect.Array:
+		if refType.Len() > 0 {
+			violations = pointerRuleViolations(
+				refType.Elem(), path+"[]", violations,
+			)
+		}
+	case reflect.Struct:
+		for i := range refType.NumField() {
+			iterField := refType.Field(i)
+			iterPath := iterField.Name
+			if path != "" {
+				iterPath = path + "." + iterField.Name
+			}
+			violations = pointerRuleViolations(
+				iterField.Type, iterPath, violations,
+			)
+		}
+	}
+	return violations
+}
+
+// Writes the results of [CGoStructGen.PointerRuleReport] to the supplied
+// writer in a human readable format. Only structs that are not safe to pass by
+// pointer are written.
+func (c *CGoStructGen) WritePointerRuleReport(w io.Writer) error {
+	for _, iterReport := range c.PointerRuleReport() {
+		if iterReport.SafeToPassByPointer {
+			continue
+		}
+		_, err := fmt.Fprintf(
+			w, "%s is not safe to pass to C by pointer:\n", iterReport.Name,
+		)
+		if err != nil {
+			return err
+		}
+		for _, v := range iterReport.Violations {
+			_, err = fmt.Fprintf(
+				w, "\t%s (%s): %s\n", v.Path, v.Kind, v.Suggestion,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
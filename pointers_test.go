@@ -0,0 +1,100 @@
+package sbcgostructgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestPointerRuleReportSafe(t *testing.T) {
+	type s2 struct{ f1 [4]int32 }
+	type s1 struct {
+		f1 int8
+		f2 uintptr
+		f3 s2
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	report := res.PointerRuleReport()
+	sbtest.Eq(t, 2, len(report))
+	for _, r := range report {
+		sbtest.True(t, r.SafeToPassByPointer)
+		sbtest.Eq(t, 0, len(r.Violations))
+	}
+}
+
+func TestPointerRuleReportViolations(t *testing.T) {
+	type s3 struct {
+		f1 int32
+		f2 string
+	}
+	type s2 struct {
+		f1 [2]*int32
+		f2 [3]s3
+	}
+	type s1 struct {
+		f1 string
+		f2 *int32
+		f3 unsafe.Pointer
+		f4 s2
+		f5 *s3
+		f6 int64
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	report := res.PointerRuleReport()
+	sbtest.Eq(t, 3, len(report))
+
+	sbtest.Eq(t, "s1", report[0].Name)
+	sbtest.False(t, report[0].SafeToPassByPointer)
+	paths := []string{}
+	kinds := []reflect.Kind{}
+	for _, v := range report[0].Violations {
+		paths = append(paths, v.Path)
+		kinds = append(kinds, v.Kind)
+		sbtest.True(t, v.Suggestion != "")
+	}
+	sbtest.SlicesMatch(t,
+		[]string{"f1", "f2", "f3", "f4.f1[]", "f4.f2[].f2", "f5"}, paths,
+	)
+	sbtest.SlicesMatch(t,
+		[]reflect.Kind{
+			reflect.String, reflect.Pointer, reflect.UnsafePointer,
+			reflect.Pointer, reflect.String, reflect.Pointer,
+		},
+		kinds,
+	)
+
+	sbtest.Eq(t, "s2", report[1].Name)
+	sbtest.False(t, report[1].SafeToPassByPointer)
+	sbtest.Eq(t, 2, len(report[1].Violations))
+
+	sbtest.Eq(t, "s3", report[2].Name)
+	sbtest.False(t, report[2].SafeToPassByPointer)
+	sbtest.Eq(t, 1, len(report[2].Violations))
+	sbtest.Eq(t, "f2", report[2].Violations[0].Path)
+}
+
+func TestWritePointerRuleReport(t *testing.T) {
+	type s2 struct{ f1 int32 }
+	type s1 struct {
+		f1 int32
+		f2 *int32
+		f3 string
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Nil(t, GenerateFor[s2](res))
+
+	var sb strings.Builder
+	sbtest.Nil(t, res.WritePointerRuleReport(&sb))
+	exp := "s1 is not safe to pass to C by pointer:\n" +
+		"\tf2 (ptr): " + pointerRuleSuggestions[reflect.Pointer] + "\n" +
+		"\tf3 (string): " + pointerRuleSuggestions[reflect.String] + "\n"
+	sbtest.Eq(t, exp, sb.String())
+}
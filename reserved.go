@@ -0,0 +1,87 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Returns the name of a reserved field at the supplied offset.
+func reservedName(offset uintptr) string {
+	return fmt.Sprintf("_reserved_%d", offset)
+}
+
+// Returns a reserved field that explicitly fills the supplied number of bytes.
+func reservedBytes(offset uintptr, size uintptr) structField {
+	return structField{
+		_type: FieldTypeUint8T.String(),
+		name:  reservedName(offset),
+		typeModifier: typeModifier{
+			typeMod:   TypeModArray,
+			tModAmnts: []int{int(size)},
+		},
+		offset:   offset,
+		size:     size,
+		align:    1,
+		reserved: true,
+	}
+}
+
+// Adds reserved fields wherever the natural C layout of the supplied fields
+// would differ from their Go offsets, and at the end if the C struct would be
+// smaller than the Go struct. This happens when zero size fields are dropped
+// or when the fields of embedded structs are flattened. The base offset is the
+// offset of the start of the struct the fields belong to.
+func padFields(
+	fields []structField,
+	baseOffset uintptr,
+	size uintptr,
+) []structField {
+	rv := make([]structField, 0, len(fields))
+	end := baseOffset
+	align := uintptr(1)
+	for _, iterField := range fields {
+		if alignUp(end, iterField.align) < iterField.offset {
+			rv = append(rv, reservedBytes(end, iterField.offset-end))
+		}
+		rv = append(rv, iterField)
+		end = iterField.offset + iterField.size
+		align = max(align, iterField.align)
+	}
+	if alignUp(end, align) < baseOffset+size {
+		rv = append(rv, reservedBytes(end, baseOffset+size-end))
+	}
+	return rv
+}
+
+// Returns the alignment the supplied type has in C. This is the same as the
+// Go alignment except for structs that contain zero size fields, which are
+// dropped in C and so do not contribute their alignment.
+func (c *CGoStructGen) cAlign(refType reflect.Type) uintptr {
+	switch refType.Kind() {
+	case reflect.Array:
+		return c.cAlign(refType.Elem())
+	case reflect.Struct:
+		if _, ok := c.opts.TypeOverrides[refType]; ok {
+			return uintptr(refType.Align())
+		}
+		if _, _, ok := cTyperFor(refType); ok {
+			return uintptr(refType.Align())
+		}
+		rv := uintptr(1)
+		for i := range refType.NumField() {
+			iterField := refType.Field(i)
+			tag, _ := parseCgoTag(iterField)
+			switch {
+			case iterField.Type.Size() == 0:
+			case tag.skip || iterField.Name == "_":
+				// Opaque fields have the same alignment as the Go type
+				rv = max(rv, uintptr(iterField.Type.Align()))
+			default:
+				rv = max(rv, c.cAlign(iterField.Type))
+			}
+		}
+		return rv
+	default:
+		return uintptr(refType.Align())
+	}
+}
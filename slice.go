@@ -0,0 +1,137 @@
+package sbcgostructgen
+
+import (
+	"cmp"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unsafe"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+var (
+	nonIdentCharRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// Returns the name of the C struct that represents a slice whose data field
+// is the supplied element field, i.e. `Slice_int32` for a `[]int32`.
+func (c *CGoStructGen) sliceStructName(elem structField) string {
+	// Some base types, such as `char*` for strings, include pointers
+	base := strings.TrimRight(elem._type, "*")
+	pntrs := elem.pntrs + len(elem._type) - len(base)
+
+	var sb strings.Builder
+	sb.WriteString("Slice_")
+	sb.WriteString(underscoreRunRegex.ReplaceAllString(
+		nonIdentCharRegex.ReplaceAllString(
+			strings.TrimSuffix(
+				c.trimTypedefAffixes(strings.TrimSpace(base)), "_t",
+			),
+			"_",
+		),
+		"_",
+	))
+	sb.WriteString(strings.Repeat("_ptr", pntrs))
+	return c.typeCase(sb.String())
+}
+
+// Returns true if the supplied type is written as a C array, either because it
+// is a Go array or because the C type from its override or [CTyper] has array
+// dimensions. Slices of such types are not allowed because the data pointer of
+// the slice struct could not express the dimensions.
+func (c *CGoStructGen) isCArray(refType reflect.Type) bool {
+	var name string
+	if o, ok := c.opts.TypeOverrides[refType]; ok {
+		name = o.Name
+	} else if n, _, ok := cTyperFor(refType); ok {
+		name = n
+	} else {
+		return refType.Kind() == reflect.Array
+	}
+	_, dims := splitCTypeDims(name)
+	return len(dims) > 0
+}
+
+// Claims the names of all slice structs that were generated. The slice types
+// are keyed by their element type, so named slice types with the same element
+// share a slice struct, but a slice struct cannot share its name with any other
+// type.
+func (c *CGoStructGen) claimSliceNames() error {
+	sliceTypes := slices.SortedFunc(
+		maps.Keys(c.sliceTypes),
+		func(l reflect.Type, r reflect.Type) int {
+			return cmp.Or(
+				strings.Compare(c.sliceTypes[l], c.sliceTypes[r]),
+				strings.Compare(l.String(), r.String()),
+			)
+		},
+	)
+	for _, t := range sliceTypes {
+		name := c.sliceTypes[t]
+		if err := c.claimTypeNames(
+			t, c.cTag(name), c.cTypedef(name),
+		); err != nil {
+			return sberr.Wrap(err, "slice struct %s", name)
+		}
+	}
+	return nil
+}
+
+// Adds the C struct that represents the slice type of the supplied field,
+// returning its name. The struct has the same layout as a Go slice header: a
+// pointer to the first element followed by the length and capacity. The slice
+// type is recorded so the name of the struct can be claimed, see
+// [CGoStructGen.claimSliceNames].
+func (c *CGoStructGen) generateSliceStruct(
+	refType reflect.Type, structName string,
+	field reflect.StructField,
+	cStructs map[string][]structField, includes map[include]struct{},
+) string {
+	// The element is generated as if it were a field of the parent struct so
+	// that it is named the same way, it is then removed from the parent
+	c.generateCStructs(
+		refType.Elem(), structName,
+		field, typeModifier{typeMod: TypeModNone},
+		cStructs, includes,
+	)
+	parentFields := cStructs[structName]
+	data := parentFields[len(parentFields)-1]
+	cStructs[structName] = parentFields[:len(parentFields)-1]
+
+	name := c.sliceStructName(data)
+	c.sliceTypes[reflect.SliceOf(refType.Elem())] = name
+	if len(cStructs[name]) > 0 {
+		return name
+	}
+
+	ptrSize := unsafe.Sizeof(uintptr(0))
+	data.typeModifier = typeModifier{
+		typeMod: TypeModPntr,
+		pntrs:   data.pntrs + 1,
+	}
+	data.name = "data"
+	data.offset, data.size, data.align = 0, ptrSize, ptrSize
+	cStructs[name] = []structField{
+		data,
+		{
+			_type:  "ptrdiff_t",
+			name:   "len",
+			offset: ptrSize,
+			size:   ptrSize,
+			align:  ptrSize,
+		},
+		{
+			_type:  "ptrdiff_t",
+			name:   "cap",
+			offset: 2 * ptrSize,
+			size:   ptrSize,
+			align:  ptrSize,
+		},
+	}
+	c.types[name] = refType
+	includes["<stddef.h>"] = struct{}{}
+	return name
+}
@@ -0,0 +1,215 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForSliceDisabled(t *testing.T) {
+	type s1 struct{ f1 []int32 }
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+}
+
+func TestGenerateForSlicePODOnly(t *testing.T) {
+	type s1 struct{ f1 []int32 }
+	err := GenerateFor[s1](New(Opts{SliceStructs: true, PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+}
+
+func TestGenerateForSliceInvalidElem(t *testing.T) {
+	type s1 struct{ f1 []map[int32]int32 }
+	err := GenerateFor[s1](New(Opts{SliceStructs: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+}
+
+func TestGenerateForSliceStructs(t *testing.T) {
+	type s2 struct{ f1 int32 }
+	type s1 struct {
+		f1 []int32
+		f2 []int32
+		f3 [2][]s2
+		f4 [][]*int8
+		f5 *[]string
+	}
+	res := New(Opts{SliceStructs: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	fields := res.structs["s1"]
+	sbtest.Eq(t, "Slice_int32_t f1", fields[0].String())
+	sbtest.Eq(t, "Slice_int32_t f2", fields[1].String())
+	sbtest.Eq(t, "Slice_s2_t f3[2]", fields[2].String())
+	sbtest.Eq(t, "Slice_Slice_int8_ptr_t f4", fields[3].String())
+	sbtest.Eq(t, "Slice_char_ptr_t* f5", fields[4].String())
+
+	dataFields := map[string]string{
+		"Slice_int32":          "int32_t* data",
+		"Slice_s2":             "s2_t* data",
+		"Slice_int8_ptr":       "int8_t** data",
+		"Slice_Slice_int8_ptr": "Slice_int8_ptr_t* data",
+		"Slice_char_ptr":       "char** data",
+	}
+	for name, data := range dataFields {
+		sliceFields := res.structs[name]
+		sbtest.Eq(t, 3, len(sliceFields))
+		sbtest.Eq(t, data, sliceFields[0].String())
+		sbtest.Eq(t, "ptrdiff_t len", sliceFields[1].String())
+		sbtest.Eq(t, "ptrdiff_t cap", sliceFields[2].String())
+		sbtest.Eq(t, 8, sliceFields[1].offset)
+		sbtest.Eq(t, 16, sliceFields[2].offset)
+	}
+	sbtest.Eq(t, len(dataFields)+2, len(res.structs))
+	sbtest.Eq(t, reflect.TypeFor[[]int32](), res.types["Slice_int32"])
+}
+
+type (
+	testIDs   []int32
+	testBytes [16]byte
+)
+
+func TestGenerateForSliceOfArrays(t *testing.T) {
+	type s1 struct{ f1 [][4]uint16 }
+	err := GenerateFor[s1](New(Opts{SliceStructs: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s2 struct{ f1 []testRawID }
+	err = GenerateFor[s2](New(Opts{SliceStructs: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s3 struct{ f1 []testBytes }
+	err = GenerateFor[s3](New(Opts{
+		SliceStructs: true,
+		TypeOverrides: map[reflect.Type]TypeOverride{
+			reflect.TypeFor[testBytes](): {Name: "uint8_t[16]"},
+		},
+	}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	// An override without dimensions makes the element a plain C type
+	res := New(Opts{
+		SliceStructs: true,
+		TypeOverrides: map[reflect.Type]TypeOverride{
+			reflect.TypeFor[testBytes](): {
+				Name: "uuid_t", Include: "<uuid/uuid.h>",
+			},
+		},
+	})
+	sbtest.Nil(t, GenerateFor[s3](res))
+	sbtest.Eq(t, "uuid_t* data", res.structs["Slice_uuid"][0].String())
+}
+
+func TestGenerateForSliceNameCollision(t *testing.T) {
+	type Slice_int32 struct{ f1 int32 }
+	type s1 struct{ f1 []int32 }
+
+	res := New(Opts{SliceStructs: true})
+	sbtest.Nil(t, GenerateFor[Slice_int32](res))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+
+	res = New(Opts{SliceStructs: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[Slice_int32](res))
+	sbtest.Eq(t, 2, len(res.structs))
+
+	// Named slice types with the same element share a slice struct
+	type s2 struct {
+		f1 testIDs
+		f2 []int32
+	}
+	res = New(Opts{SliceStructs: true})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, "Slice_int32_t f1", res.structs["s2"][0].String())
+	sbtest.Eq(t, "Slice_int32_t f2", res.structs["s2"][1].String())
+}
+
+func TestPointerRuleReportSliceStructs(t *testing.T) {
+	type s1 struct {
+		f1 int32
+		f2 []int32
+	}
+	res := New(Opts{SliceStructs: true})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	report := res.PointerRuleReport()
+	sbtest.Eq(t, 2, len(report))
+	sbtest.Eq(t, "Slice_int32", report[0].Name)
+	sbtest.False(t, report[0].SafeToPassByPointer)
+	sbtest.Eq(t, 1, len(report[0].Violations))
+	sbtest.Eq(t, "data", report[0].Violations[0].Path)
+	sbtest.Eq(t, reflect.Slice, report[0].Violations[0].Kind)
+
+	sbtest.Eq(t, "s1", report[1].Name)
+	sbtest.False(t, report[1].SafeToPassByPointer)
+	sbtest.Eq(t, 1, len(report[1].Violations))
+	sbtest.Eq(t, "f2", report[1].Violations[0].Path)
+	sbtest.Eq(t, reflect.Slice, report[1].Violations[0].Kind)
+}
+
+func TestWriteSliceStructs(t *testing.T) {
+	type Point struct{ x, y float32 }
+	type Batch struct {
+		points []Point
+		ids    []uint64
+	}
+	res := New(Opts{SliceStructs: true})
+	err := GenerateFor[Batch](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/slices.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/slices.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <math.h>
+#include <stdalign.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct Point{
+		float_t x;
+		float_t y;
+	} Point_t;
+
+	typedef struct Slice_Point{
+		Point_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_Point_t;
+
+	typedef struct Slice_uint64{
+		uint64_t* data;
+		ptrdiff_t len;
+		ptrdiff_t cap;
+	} Slice_uint64_t;
+
+	typedef struct Batch{
+		Slice_Point_t points;
+		Slice_uint64_t ids;
+	} Batch_t;
+
+	static_assert(sizeof(ptrdiff_t) == 8, "ptrdiff_t must have the same size as the Go type int");
+	static_assert(alignof(ptrdiff_t) == 8, "ptrdiff_t must have the same alignment as the Go type int");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
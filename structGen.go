@@ -10,6 +10,8 @@ import (
 	"os"
 	"reflect"
 	"slices"
+	"strings"
+	"sync"
 
 	sberr "github.com/barbell-math/smoothbrain-errs"
 )
@@ -44,19 +46,84 @@ type (
 
 	typeModifier struct {
 		typeMod
-		tModAmnt int
+		// The length of each array dimension, outermost first
+		tModAmnts []int
+		// The number of pointers applied to the base type. For arrays the
+		// pointers are applied to the element type.
+		pntrs int
 	}
 
 	structField struct {
 		typeModifier
-		_type string
-		name  string
+		_type  string
+		name   string
+		offset uintptr
+		size   uintptr
+		align  uintptr
+		// The name of the C struct the field refers to, empty if the field does
+		// not refer to a struct
+		structRef string
+		// The names of the macros that hold the length of each array
+		// dimension, empty if array lengths are written as numbers
+		lenMacros []string
+		// The fields of an anonymous struct that is written inline, empty if
+		// the field is not an inline struct
+		inline []structField
+		// The doc comment of the Go field, see [Opts.DocComments]
+		doc string
+		// True if the field only fills space that is padding in Go, such as
+		// the fields added for dropped zero size fields and blank fields
+		reserved bool
 	}
 
 	CGoStructGen struct {
-		opts     Opts
+		// Guards the options and the generator state so that types can be
+		// added and written from multiple goroutines
+		mu   sync.Mutex
+		opts Opts
+		genState
+	}
+
+	// All of the state that is built up as types are added. It is kept
+	// separate from the options so that it can be restored when adding a type
+	// fails, see [genState.clone].
+	genState struct {
 		includes map[include]struct{}
 		structs  map[string][]structField
+		types    map[string]reflect.Type
+		enums    map[reflect.Type]cEnum
+		typedefs map[string]reflect.Type
+		consts   map[string]cConst
+		// Maps array length macro names to the struct field they belong to
+		macros map[string]string
+		// Maps layout hash macro names to the C structs they belong to, see
+		// [Opts.EmitLayoutHashes]
+		hashMacros map[string]string
+		// Maps C type names returned by [CTyper]s to their Go types
+		cTypes map[string]reflect.Type
+		// The complex kinds that need a pair struct written to the header
+		complexPairs map[reflect.Kind]struct{}
+		// Maps C handle type names to the Go types they refer to
+		handles map[string]reflect.Type
+		// The struct types that were marked as opaque with the `cgo` tag
+		opaqueTypes map[reflect.Type]struct{}
+		// Maps the C names of opaque structs to their Go types
+		opaques map[string]reflect.Type
+		// Maps the C names of all tags and typedefs to the Go types they
+		// belong to, see [NamingPolicy]
+		typeNames map[string]reflect.Type
+		// The doc comments of the struct types that were added, and of all
+		// the struct types in the packages they were loaded from
+		docs    map[reflect.Type]typeDoc
+		pkgDocs map[string]map[string]typeDoc
+		// Maps the C names of the structs passed to [GenerateFor] to their Go
+		// types, see [CGoStructGen.regenerate]
+		roots map[string]reflect.Type
+		// Maps the slice types that were given a slice struct to the C names
+		// of those structs, see [Opts.SliceStructs]
+		sliceTypes map[reflect.Type]string
+		// True if types were added since the structs were last regenerated
+		stale bool
 	}
 
 	// Options that get passed to [New] when creating a [CGoStructGen] struct.
@@ -68,6 +135,169 @@ type (
 		// has a name found in the keys of this map the corresponding C struct
 		// will have the value from the map.
 		StructRename map[string]string
+		// Maps Go type names to C typedef names. Named Go types that are not
+		// structs, such as `type Meters float64`, are written as C typedefs,
+		// i.e. `typedef double_t Meters_t;`. If a named type has a name found
+		// in the keys of this map the corresponding typedef will have the value
+		// from the map. This also applies to enums added with [GenerateEnum].
+		TypedefRename map[string]string
+		// If true a `<STRUCT>_LAYOUT_HASH` define will be written for every
+		// struct along with a `<HEADER>_LAYOUT_HASH` define for the entire
+		// header. The values match those returned by [CGoStructGen.LayoutHash]
+		// and [CGoStructGen.HeaderLayoutHash], and the Go constants written by
+		// [CGoStructGen.WriteGoLayoutHashesTo]. Structs whose macros would have
+		// the same name, i.e. `Pa` and `PA`, are rejected with a
+		// [DuplicateNameErr].
+		EmitLayoutHashes bool
+		// If true only plain data types will be accepted, making the generated
+		// structs safe to place in shared memory or write to disk. Strings,
+		// pointers, unsafe pointers, and uintptrs will all be rejected with a
+		// [NonPODTypeErr]. Blank fields and fields that are skipped with the
+		// `cgo` tag are still part of the struct's memory, so they are checked
+		// as well.
+		PODOnly bool
+		// If true a function that maps enum values to their names will be
+		// written for every enum that was added through [GenerateEnum].
+		EmitEnumNames bool
+		// If true constants added with [GenerateConst] will be written as
+		// `static const` values rather than `#define`s.
+		StaticConsts bool
+		// If true a `#define` holding the length of every array dimension will
+		// be written before each struct and used in the field declarations in
+		// place of the literal length.
+		EmitArrayLenMacros bool
+		// The pattern used to name the array length macros. The following
+		// placeholders are replaced:
+		//   - {STRUCT}: the upper case C struct name
+		//   - {FIELD}: the upper case C field name
+		//   - {Struct}: the C struct name
+		//   - {Field}: the C field name
+		//   - {DIM}: the index of the array dimension, starting at 0
+		//
+		// If the pattern does not contain {DIM} then `_<dim>` is appended to the
+		// names of all dimensions other than the first. Defaults to
+		// `{STRUCT}_{FIELD}_LEN`.
+		ArrayLenMacroPattern string
+		// If true, and [Opts.EmitArrayLenMacros] is true, array dimensions
+		// whose length is a named Go constant use the name of that constant
+		// for their macro instead of [Opts.ArrayLenMacroPattern], i.e. a
+		// `f1 [MaxItems]int32` field is written as `int32_t f1[MaxItems]`
+		// after a `#define MaxItems 4`. Fields sized by the same constant
+		// share the macro. The constants are read from the source of the
+		// package each struct is declared in, the same way as
+		// [Opts.DocComments], and must be declared in that package.
+		// Constants whose value cannot be worked out from the source alone,
+		// i.e. ones that use iota, fall back to the pattern.
+		ArrayLenConstNames bool
+		// Maps Go kinds to the C types that should be used in place of the
+		// default C types, i.e. `double` instead of `double_t` for float64.
+		// The overrides also apply to the base types of typedefs, enums, and
+		// static constants. Array, pointer, and struct kinds cannot be
+		// overridden.
+		//
+		// The size of the C type must match the size of the Go kind. For
+		// common fixed size C types this is checked when the type is added,
+		// for all other C types static asserts are written to the header.
+		KindOverrides map[reflect.Kind]TypeOverride
+		// Maps specific Go types to the C types that should be used for them.
+		// These take priority over all other ways of determining the C type of
+		// a field, including [Opts.KindOverrides] and [CTyper]. The size of the
+		// C type is checked in the same way as [Opts.KindOverrides]. As with
+		// [CTyper] the C type may end with array dimensions, i.e.
+		// `uint8_t[16]`, which kind overrides may not.
+		TypeOverrides map[reflect.Type]TypeOverride
+		// If true complex64 and complex128 values will be written as structs
+		// with `re` and `im` members, i.e. `complex64_t` and `complex128_t`,
+		// rather than as the C99 `float _Complex` and `double _Complex` types.
+		// This is intended for C++ consumers, which do not support the C99
+		// complex types. The layout of the structs matches the Go types. When
+		// the C99 types are used `<complex.h>` is included, so its `I` macro
+		// cannot be used as the name of a field, constant, or type.
+		ComplexPairStructs bool
+		// Controls how the platform width int and uint types are written in C.
+		// By default they are rejected because their size depends on the
+		// target, see [IntMapping] for the available mappings. Static asserts
+		// are written to the header to verify that the width of the C type
+		// matches the width of the Go type.
+		IntMapping IntMapping
+		// If true slices will be written as structs with the same layout as a
+		// Go slice header, i.e. a `[]int32` is written as a `Slice_int32_t`
+		// that has an `int32_t* data` field followed by `ptrdiff_t len` and
+		// `ptrdiff_t cap` fields. One struct is written per element type.
+		// Slices whose element is written as a C array are rejected, and the
+		// name of a slice struct may not be used by any other type.
+		//
+		// The backing array of a slice is Go memory, so any struct containing
+		// a slice is reported by [CGoStructGen.PointerRuleReport].
+		SliceStructs bool
+		// If true fields that hold a runtime/cgo.Handle to a named map,
+		// interface, or func type will be written as opaque handles, i.e. a
+		// field of type `CallbacksHandle` that refers to a
+		// `type Callbacks map[string]func()` is written as a
+		// `CallbacksHandle` which is typedef'd as a `uintptr_t`. Use
+		// [GenerateHandle] and [CGoStructGen.WriteGoHandlesTo] to generate
+		// the Go handle types along with the helpers that create, resolve,
+		// and delete them. Any named uintptr type with the same Value and
+		// Delete methods as runtime/cgo.Handle is treated as a handle, a
+		// plain runtime/cgo.Handle is written as a `uintptr_t`.
+		//
+		// Map, interface, and func fields cannot be passed to C, so they are
+		// always rejected and must be replaced by their handle type.
+		HandleFields bool
+		// Controls how anonymous struct types are written in C. By default
+		// they are rejected with an [AnonymousNameErr], see
+		// [AnonymousStructMode] for the alternatives.
+		AnonymousStructs AnonymousStructMode
+		// Controls how identifiers that cannot be used in C or C++ are
+		// handled. This includes C and C++ keywords, reserved identifiers that
+		// start with an underscore and a capital letter or contain a double
+		// underscore, and identifiers with non-ASCII characters. By default
+		// they are rejected, see [IdentifierEscapeMode] for the alternatives.
+		// The escaping applies to all generated names, including names given
+		// through struct tags, [Opts.StructRename], and [Opts.TypedefRename].
+		IdentifierEscaping IdentifierEscapeMode
+		// The suffix that is appended to keywords when
+		// [Opts.IdentifierEscaping] is [IdentifierEscapeMangle]. Defaults to
+		// `_`. By default typedef names have a `_t` suffix, so a struct named
+		// after a keyword needs a suffix that does not end with an underscore.
+		IdentifierEscapeSuffix string
+		// Controls how the names of the generated C types and fields are
+		// formed, i.e. the case of struct and field names and the patterns
+		// used for struct tags and typedefs. By default Go names are used as
+		// they are and typedefs are given a `_t` suffix. Names are checked for
+		// collisions after the policy has been applied.
+		Naming NamingPolicy
+		// Controls how embedded structs are written in C. By default they are
+		// written as a member named after the embedded type, see
+		// [EmbeddedFieldMode] for the alternatives. The mode can be set per
+		// field with the `embed` option of the `cgo` tag, i.e.
+		// `cgo:"embed=flatten"`. Embedded structs that have a C type from
+		// [Opts.TypeOverrides] or [CTyper] are always written as a member.
+		EmbeddedFields EmbeddedFieldMode
+		// The struct types that are only visible to C through pointers. They
+		// are written as forward declarations without a body, i.e.
+		// `typedef struct Foo Foo_t;`, so C code cannot access their fields.
+		// Their fields are not checked so they may hold types that have no C
+		// representation. Using an opaque struct by value in another struct,
+		// including in an array, is an error. A struct can also be made opaque
+		// with the `opaque` option of the `cgo` tag on a field that points to
+		// it, i.e. `cgo:"opaque=true"`.
+		OpaqueStructs []reflect.Type
+		// Maps Go package paths to the names of the headers their types are
+		// written to by [CGoStructGen.WriteHeadersTo], i.e. `"geom"` to write
+		// the types of a package to `geom.h`. Multiple packages can share a
+		// header. Packages that are not in the map are written to a header
+		// named after the last element of their path.
+		HeaderGroups map[string]string
+		// Controls whether the doc comments of Go structs and their fields are
+		// written to the header, see [DocCommentStyle] for the available
+		// styles. The comments are read from the source of the package each
+		// struct is declared in, which must be available to the go tool, i.e.
+		// it must be part of the main module or one of its dependencies. Only
+		// structs declared at the package level have doc comments. Each struct
+		// comment also names the Go package and type the struct was generated
+		// from.
+		DocComments DocCommentStyle
 	}
 )
 
@@ -75,6 +305,9 @@ var (
 	InvalidTypeErr        = errors.New("Invalid Type")
 	UnderspecifiedTypeErr = errors.New("Underspecified type")
 	AnonymousNameErr      = errors.New("Anonymous name")
+	NonPODTypeErr         = errors.New("Non plain data type")
+	InvalidTagErr         = errors.New("Invalid cgo tag")
+	DuplicateNameErr      = errors.New("Duplicate name")
 
 	reflectToEnumTypes = map[reflect.Kind]fieldType{
 		reflect.Uintptr:       FieldTypeVoid,
@@ -108,17 +341,50 @@ var (
 	}
 )
 
+// Returns the C type of the field without the field name, i.e. `int32_t*` or
+// `uint32_t[5]`.
+func (s structField) typeString() string {
+	return s.baseType() + strings.Repeat("*", s.pntrs) + s.dimsString(false)
+}
+
+// Returns the C type of the field without any pointers or array dimensions.
+// Inline structs are written on a single line.
+func (s structField) baseType() string {
+	if s.inline == nil {
+		return s._type
+	}
+	var sb strings.Builder
+	sb.WriteString("struct{")
+	for _, iterField := range s.inline {
+		sb.WriteString(" ")
+		sb.WriteString(iterField.String())
+		sb.WriteString(";")
+	}
+	sb.WriteString(" }")
+	return sb.String()
+}
+
+func (s structField) dimsString(useMacros bool) string {
+	var sb strings.Builder
+	for i, dim := range s.tModAmnts {
+		if useMacros && i < len(s.lenMacros) {
+			fmt.Fprintf(&sb, "[%s]", s.lenMacros[i])
+		} else {
+			fmt.Fprintf(&sb, "[%d]", dim)
+		}
+	}
+	return sb.String()
+}
+
 func (s structField) String() string {
-	switch s.typeModifier.typeMod {
-	case TypeModPntr:
-		return fmt.Sprintf("%s* %s", s._type, s.name)
-	case TypeModArray:
-		return fmt.Sprintf("%s %s[%d]", s._type, s.name, s.tModAmnt)
-	case TypeModNone:
-		fallthrough
-	default:
-		return fmt.Sprintf("%s %s", s._type, s.name)
+	if s.name == "" {
+		// An anonymous member
+		return s.baseType()
 	}
+	return fmt.Sprintf(
+		"%s%s %s%s",
+		s.baseType(), strings.Repeat("*", s.pntrs), s.name, s.dimsString(true),
+	)
 }
 func (i include) String() string {
 	return "#include " + string(i)
@@ -127,9 +393,56 @@ func (i include) String() string {
 // Creates a new struct generator.
 func New(opts Opts) *CGoStructGen {
 	return &CGoStructGen{
-		opts:     opts,
-		includes: map[include]struct{}{},
-		structs:  map[string][]structField{},
+		opts: opts,
+		genState: genState{
+			includes: map[include]struct{}{},
+			structs:  map[string][]structField{},
+			types:    map[string]reflect.Type{},
+			enums:    map[reflect.Type]cEnum{},
+			typedefs: map[string]reflect.Type{},
+			consts:   map[string]cConst{},
+			macros:   map[string]string{},
+			cTypes:   map[string]reflect.Type{},
+
+			hashMacros: map[string]string{},
+
+			complexPairs: map[reflect.Kind]struct{}{},
+			handles:      map[string]reflect.Type{},
+			typeNames:    map[string]reflect.Type{},
+			opaqueTypes:  map[reflect.Type]struct{}{},
+			opaques:      map[string]reflect.Type{},
+			docs:         map[reflect.Type]typeDoc{},
+			pkgDocs:      map[string]map[string]typeDoc{},
+			roots:        map[string]reflect.Type{},
+			sliceTypes:   map[reflect.Type]string{},
+		},
+	}
+}
+
+// Returns a copy of the generator state that is not affected by any changes
+// made to the original. Struct fields are never modified in place once they
+// are generated, so the field slices can be shared.
+func (s *genState) clone() genState {
+	return genState{
+		includes:     maps.Clone(s.includes),
+		structs:      maps.Clone(s.structs),
+		types:        maps.Clone(s.types),
+		enums:        maps.Clone(s.enums),
+		typedefs:     maps.Clone(s.typedefs),
+		consts:       maps.Clone(s.consts),
+		macros:       maps.Clone(s.macros),
+		hashMacros:   maps.Clone(s.hashMacros),
+		cTypes:       maps.Clone(s.cTypes),
+		complexPairs: maps.Clone(s.complexPairs),
+		handles:      maps.Clone(s.handles),
+		typeNames:    maps.Clone(s.typeNames),
+		opaqueTypes:  maps.Clone(s.opaqueTypes),
+		opaques:      maps.Clone(s.opaques),
+		docs:         maps.Clone(s.docs),
+		pkgDocs:      maps.Clone(s.pkgDocs),
+		roots:        maps.Clone(s.roots),
+		sliceTypes:   maps.Clone(s.sliceTypes),
+		stale:        s.stale,
 	}
 }
 
@@ -139,62 +452,186 @@ func New(opts Opts) *CGoStructGen {
 //   - int8, int16, int32, int64
 //   - uint8, uint16, uint32, uint64
 //   - float32, float64
+//   - int, uint (see [Opts.IntMapping])
+//   - slices (see [Opts.SliceStructs])
+//   - handles to named maps, interfaces, and funcs (see [Opts.HandleFields])
+//   - complex64, complex128 (see [Opts.ComplexPairStructs])
 //   - string
 //   - bool
 //   - uintptr, unsafe.Pointer
 //   - arrays and structs that are composed of the above types
+//   - named types whose underlying type is one of the above, which are written
+//     as C typedefs (see [Opts.TypedefRename])
+//   - any type that implements [CTyper]
+//
+// Fields named `_` are written as reserved padding and zero size fields, such
+// as `struct{}` or `[0]int32`, are dropped. Explicit padding is added wherever
+// needed so the size of each C struct and the offsets of its fields match the
+// Go struct.
+//
+// Opaque structs (see [Opts.OpaqueStructs]) are written as forward
+// declarations and can only be referred to through pointers.
 //
 // Types will be recursively added. Types that are duplicated between struct
 // definitions will not be duplicated in the output C code.
 //
 // This funciton is intended to be called many times with the same value for the
 // `t` argument. The `t` value will be updated with any newly-found structs.
+//
+// It is safe to call this function from multiple goroutines, including
+// concurrently with [GenerateEnum], [GenerateConst], and [CGoStructGen.WriteTo].
+// The generated code does not depend on the order types were added in.
 func GenerateFor[T any](c *CGoStructGen) error {
 	var err error
+	var structName string
 	refType := reflect.TypeFor[T]()
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	saved := c.genState.clone()
+
 	if refType.Kind() != reflect.Struct {
 		err = sberr.Wrap(
 			InvalidTypeErr, "Expected struct, got %s", refType.Kind(),
 		)
 		goto errExit
 	}
+	if err = c.checkKindOverrides(); err != nil {
+		goto errExit
+	}
+	if _, _, ok := cTyperFor(refType); ok {
+		err = sberr.Wrap(
+			InvalidTypeErr,
+			"%s implements CTyper so it already has a C representation",
+			refType,
+		)
+		goto errExit
+	}
+	if c.isOpaque(refType) {
+		// Only the forward declaration is written
+		err = c.checkOpaque(refType, "")
+		goto errExit
+	}
 
-	if err = c.checkType(refType, "", c.structs); err != nil {
+	if err = c.checkType(refType, "", "", c.structs); err != nil {
 		goto errExit
 	}
 	c.generateCStructs(
-		refType, "",
-		"", typeModifier{typeMod: TypeModNone},
+		refType, "", reflect.StructField{}, typeModifier{typeMod: TypeModNone},
 		c.structs, c.includes,
 	)
+	if err = c.claimSliceNames(); err != nil {
+		goto errExit
+	}
+	if err = c.claimLayoutHashNames(); err != nil {
+		goto errExit
+	}
+	if err = c.checkComplexIdents(); err != nil {
+		goto errExit
+	}
+	if err = c.checkConstNames(); err != nil {
+		goto errExit
+	}
+	structName, _, _ = c.cStructName(refType, "")
+	c.roots[structName] = refType
+	c.stale = true
 
 errExit:
-	if err != nil && c.opts.ExitOnErr {
-		log.Fatal(err)
+	if err != nil {
+		// Nothing from a type that could not be added is left behind
+		c.genState = saved
+		if c.opts.ExitOnErr {
+			log.Fatal(err)
+		}
 	}
 	return err
 }
 
+// Checks that the supplied type can be translated to C. The anonName argument
+// is the name an anonymous struct would be given at the current position in
+// the type graph, see [CGoStructGen.cStructName].
 func (c *CGoStructGen) checkType(
 	refType reflect.Type,
 	fieldName string,
+	anonName string,
 	cStructs map[string][]structField,
 ) error {
+	if c.opts.PODOnly {
+		switch refType.Kind() {
+		case reflect.String, reflect.Pointer, reflect.UnsafePointer,
+			reflect.Uintptr, reflect.Slice:
+			return sberr.Wrap(
+				NonPODTypeErr,
+				"A %s is pointer-like and is not allowed when only plain data is allowed, field %s",
+				refType.Kind(), fieldName,
+			)
+		}
+	}
+
+	if o, ok := c.opts.TypeOverrides[refType]; ok {
+		if err := c.checkTypeOverride(refType, o); err != nil {
+			return sberr.Wrap(err, "field %s", fieldName)
+		}
+		return nil
+	}
+	if name, includes, ok := cTyperFor(refType); ok {
+		if err := c.checkExternalCType(
+			refType, name, includes, true,
+		); err != nil {
+			return sberr.Wrap(err, "field %s", fieldName)
+		}
+		return nil
+	}
+	if valueType, ok := c.handleValueType(refType); ok {
+		return c.checkHandle(valueType, fieldName)
+	}
+
+	if _, ok := c.enums[refType]; !ok {
+		if name, ok := c.typedefName(refType); ok {
+			if err := c.checkTypedefName(refType, name); err != nil {
+				return sberr.Wrap(err, "field %s", fieldName)
+			}
+			c.typedefs[name] = refType
+		}
+	}
+	if o, ok := c.opts.KindOverrides[refType.Kind()]; ok {
+		if err := c.checkTypeOverride(refType, o); err != nil {
+			return sberr.Wrap(err, "field %s", fieldName)
+		}
+		return nil
+	}
+
 	switch refType.Kind() {
-	case reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface,
-		reflect.Complex64, reflect.Complex128:
+	case reflect.Map, reflect.Func, reflect.Interface:
+		if c.opts.HandleFields {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"Cannot translate a Go %s to C, use a handle to it instead (see GenerateHandle), field %s",
+				refType.Kind(), fieldName,
+			)
+		}
 		return sberr.Wrap(
 			InvalidTypeErr,
-			"Cannot translate a Go %s to C, field %s",
+			"Cannot translate a Go %s to C unless it is held by a handle and handle fields are enabled, field %s",
 			refType.Kind(), fieldName,
 		)
-	case reflect.Int, reflect.Uint:
+	case reflect.Chan:
 		return sberr.Wrap(
-			UnderspecifiedTypeErr,
-			"A %s can be varying sizes in C, specify bit size to fix (i.e. int32 instead of int), field %s",
+			InvalidTypeErr,
+			"Cannot translate a Go %s to C, field %s",
 			refType.Kind(), fieldName,
 		)
+	case reflect.Int, reflect.Uint:
+		if c.opts.IntMapping == IntMappingNone {
+			return sberr.Wrap(
+				UnderspecifiedTypeErr,
+				"A %s can be varying sizes in C, specify bit size to fix (i.e. int32 instead of int) or set an int mapping, field %s",
+				refType.Kind(), fieldName,
+			)
+		}
+		if err := c.checkPlatformInt(refType.Kind()); err != nil {
+			return sberr.Wrap(err, "field %s", fieldName)
+		}
 	case reflect.UnsafePointer, reflect.Uintptr:
 		fmt.Printf(
 			"WARN: Cannot validate kind of %s, will be specified in C as a void*, field %s\n",
@@ -203,61 +640,327 @@ func (c *CGoStructGen) checkType(
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 	case reflect.Float32, reflect.Float64:
+	case reflect.Complex64, reflect.Complex128:
+		if c.opts.ComplexPairStructs {
+			if err := c.checkComplexPair(refType.Kind()); err != nil {
+				return sberr.Wrap(err, "field %s", fieldName)
+			}
+		}
 	case reflect.Bool:
 	case reflect.String:
 	case reflect.Array, reflect.Pointer:
-		return c.checkType(refType.Elem(), fieldName, cStructs)
-	case reflect.Struct:
-		newStructName := refType.Name()
-		if newStructName == "" {
+		if elem, indirect := indirectElem(refType); indirect &&
+			elem.Kind() == reflect.Struct && c.isOpaque(elem) {
+			return c.checkOpaque(elem, fieldName)
+		}
+		return c.checkType(refType.Elem(), fieldName, anonName, cStructs)
+	case reflect.Slice:
+		if !c.opts.SliceStructs {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"Cannot translate a Go slice to C unless slice structs are enabled, field %s",
+				fieldName,
+			)
+		}
+		if c.isCArray(refType.Elem()) {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"A slice of arrays cannot be translated to C, field %s",
+				fieldName,
+			)
+		}
+		elem := refType.Elem()
+		for elem.Kind() == reflect.Array || elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if c.opts.AnonymousStructs == AnonymousStructsInline &&
+			elem.Kind() == reflect.Struct && elem.Name() == "" {
 			return sberr.Wrap(
 				AnonymousNameErr,
-				"Anonymous structs are not supported, add a name, field %s\n",
+				"A slice of anonymous structs cannot be written inline, field %s",
 				fieldName,
 			)
 		}
-		if rename, ok := c.opts.StructRename[newStructName]; ok {
-			newStructName = rename
+		if err := c.checkExternalCType(
+			platformIntTypes[reflect.Int], "ptrdiff_t", nil, true,
+		); err != nil {
+			return sberr.Wrap(err, "field %s", fieldName)
+		}
+		if elem, _ := indirectElem(refType); elem.Kind() == reflect.Struct &&
+			c.isOpaque(elem) {
+			return c.checkOpaque(elem, fieldName)
+		}
+		return c.checkType(refType.Elem(), fieldName, anonName, cStructs)
+	case reflect.Struct:
+		if c.isOpaque(refType) {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"%s is opaque and can only be used through a pointer, field %s",
+				refType, fieldName,
+			)
+		}
+		newStructName, inline, err := c.cStructName(refType, anonName)
+		if err != nil {
+			return sberr.Wrap(err, "field %s", fieldName)
 		}
-		if _, ok := cStructs[newStructName]; !ok {
+		if other, ok := c.types[newStructName]; ok && other != refType {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The C struct %s is used by both %s and %s, field %s",
+				newStructName, other, refType, fieldName,
+			)
+		}
+		if !inline {
+			if err := c.claimTypeNames(
+				refType, c.cTag(newStructName), c.cTypedef(newStructName),
+			); err != nil {
+				return sberr.Wrap(err, "field %s", fieldName)
+			}
+		}
+		c.types[newStructName] = refType
+		if _, ok := cStructs[newStructName]; !ok && !inline {
 			cStructs[newStructName] = make([]structField, 0)
 		}
 
-		for i := range refType.NumField() {
-			iterField := refType.Field(i)
+		if refType.Size() == 0 {
+			return sberr.Wrap(
+				InvalidTypeErr,
+				"C has no zero size types, %s cannot be referenced or used as a root, field %s",
+				refType, fieldName,
+			)
+		}
+		return c.checkStructFields(
+			refType, newStructName, fieldName, 0,
+			map[string]struct{}{}, cStructs,
+		)
+	}
+	return nil
+}
 
-			var iterFieldName string
-			if fieldName == "" {
-				iterFieldName = iterField.Name
-			} else {
-				iterFieldName += fieldName + "." + iterField.Name
+// Checks the fields of the supplied struct type, which are written to the C
+// struct with the supplied name. The cNames argument holds the C names of the
+// fields that were already checked for the C struct, it is shared with any
+// embedded structs that are flattened or written as anonymous members. The
+// base offset is the offset of the supplied struct type within the C struct.
+func (c *CGoStructGen) checkStructFields(
+	refType reflect.Type,
+	structName string,
+	fieldName string,
+	baseOffset uintptr,
+	cNames map[string]struct{},
+	cStructs map[string][]structField,
+) error {
+	if err := c.checkDocs(refType); err != nil {
+		return sberr.Wrap(err, "field %s", fieldName)
+	}
+	for i := range refType.NumField() {
+		iterField := refType.Field(i)
+		iterField.Offset += baseOffset
+
+		var iterFieldName string
+		if fieldName == "" {
+			iterFieldName = iterField.Name
+		} else {
+			iterFieldName += fieldName + "." + iterField.Name
+		}
+
+		tag, err := parseCgoTag(iterField)
+		if err != nil {
+			return sberr.Wrap(err, "field %s", iterFieldName)
+		}
+		if err := c.checkEmbedTag(iterField, tag); err != nil {
+			return sberr.Wrap(err, "field %s", iterFieldName)
+		}
+		if err := c.checkOpaqueTag(iterField, tag); err != nil {
+			return sberr.Wrap(err, "field %s", iterFieldName)
+		}
+		if iterField.Type.Size() == 0 {
+			// C has no zero size types so the field is dropped, any padding
+			// Go adds for it is written explicitly
+			continue
+		}
+		if !tag.skip && c.embedMode(iterField, tag) != EmbeddedFieldsNested {
+			if c.isOpaque(iterField.Type) {
+				return sberr.Wrap(
+					InvalidTypeErr,
+					"%s is opaque and cannot be embedded, field %s",
+					iterField.Type, iterFieldName,
+				)
+			}
+			// The promoted fields share the namespace of the parent struct
+			if err := c.checkStructFields(
+				iterField.Type, structName, iterFieldName, iterField.Offset,
+				cNames, cStructs,
+			); err != nil {
+				return err
 			}
+			continue
+		}
 
-			if err := c.checkType(
-				iterField.Type, iterFieldName, cStructs,
+		cName := c.cFieldName(iterField)
+		if err := checkIdent(cName); err != nil {
+			return sberr.Wrap(err, "field %s", iterFieldName)
+		}
+		if _, ok := cNames[cName]; ok {
+			return sberr.Wrap(
+				DuplicateNameErr,
+				"The C name %s is used by multiple fields, field %s",
+				cName, iterFieldName,
+			)
+		}
+		cNames[cName] = struct{}{}
+		if tag.skip || iterField.Name == "_" {
+			// The field is still copied along with the rest of the struct
+			if err := c.checkSkippedPOD(
+				iterField.Type, iterFieldName,
 			); err != nil {
 				return err
 			}
+			continue
 		}
+		if err := c.checkArrayLenMacros(
+			structName, cName, iterField.Type,
+			c.arrayLenConsts(refType, iterField),
+		); err != nil {
+			return sberr.Wrap(err, "field %s", iterFieldName)
+		}
+		if err := c.checkTagType(iterField.Type, tag); err != nil {
+			return sberr.Wrap(err, "field %s", iterFieldName)
+		}
+
+		if err := c.checkType(
+			iterField.Type, iterFieldName,
+			anonStructName(structName, cName), cStructs,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks that a blank or skipped field, which is not translated to C, is plain
+// data when [Opts.PODOnly] is true. The field is written as opaque bytes, but it is
+// still part of the memory of the struct, so any pointers it holds would be
+// copied along with it.
+func (c *CGoStructGen) checkSkippedPOD(
+	refType reflect.Type,
+	fieldName string,
+) error {
+	if !c.opts.PODOnly {
+		return nil
+	}
+	if kind, ok := pointerLikeKind(refType); ok {
+		return sberr.Wrap(
+			NonPODTypeErr,
+			"A %s is pointer-like and is not allowed when only plain data is allowed, field %s",
+			kind, fieldName,
+		)
 	}
 	return nil
 }
 
+// Returns the first pointer-like kind that the supplied type holds, searching
+// through arrays and struct fields.
+func pointerLikeKind(refType reflect.Type) (reflect.Kind, bool) {
+	switch refType.Kind() {
+	case reflect.String, reflect.Pointer, reflect.UnsafePointer,
+		reflect.Uintptr, reflect.Slice, reflect.Map, reflect.Func,
+		reflect.Interface, reflect.Chan:
+		return refType.Kind(), true
+	case reflect.Array:
+		return pointerLikeKind(refType.Elem())
+	case reflect.Struct:
+		for i := range refType.NumField() {
+			if kind, ok := pointerLikeKind(refType.Field(i).Type); ok {
+				return kind, true
+			}
+		}
+	}
+	return reflect.Invalid, false
+}
+
 func (c *CGoStructGen) generateCStructs(
 	refType reflect.Type, structName string,
-	fieldName string, tMod typeModifier,
+	field reflect.StructField, tMod typeModifier,
 	cStructs map[string][]structField, includes map[include]struct{},
 ) {
-	if e, ok := reflectToEnumTypes[refType.Kind()]; ok {
+	if o, ok := c.opts.TypeOverrides[refType]; ok {
+		cStructs[structName] = append(
+			cStructs[structName], c.externalField(o.Name, field, tMod),
+		)
+		if o.Include != "" {
+			includes[include(o.Include)] = struct{}{}
+		}
+		return
+	}
+	if name, cIncludes, ok := cTyperFor(refType); ok {
+		cStructs[structName] = append(
+			cStructs[structName], c.externalField(name, field, tMod),
+		)
+		for _, i := range cIncludes {
+			includes[include(i)] = struct{}{}
+		}
+		return
+	}
+	if valueType, ok := c.handleValueType(refType); ok {
+		cStructs[structName] = append(
+			cStructs[structName],
+			structField{
+				_type:        c.handleCType(valueType),
+				name:         c.cFieldName(field),
+				typeModifier: tMod,
+				offset:       field.Offset,
+				size:         field.Type.Size(),
+				align:        uintptr(field.Type.Align()),
+			},
+		)
+		includes["<stdint.h>"] = struct{}{}
+		return
+	}
+	if enum, ok := c.enums[refType]; ok {
 		cStructs[structName] = append(
 			cStructs[structName],
 			structField{
-				_type:        e.String(),
-				name:         fieldName,
+				_type:        c.cTypedef(enum.name),
+				name:         c.cFieldName(field),
 				typeModifier: tMod,
+				offset:       field.Offset,
+				size:         field.Type.Size(),
+				align:        uintptr(field.Type.Align()),
 			},
 		)
-		if i, ok := reflectToIncludes[refType.Kind()]; ok {
+		return
+	}
+	if name, ok := c.typedefName(refType); ok {
+		cStructs[structName] = append(
+			cStructs[structName],
+			structField{
+				_type:        c.cTypedef(name),
+				name:         c.cFieldName(field),
+				typeModifier: tMod,
+				offset:       field.Offset,
+				size:         field.Type.Size(),
+				align:        uintptr(field.Type.Align()),
+			},
+		)
+		if _, i, _ := c.kindCType(refType.Kind()); i != "" {
+			includes[i] = struct{}{}
+		}
+		return
+	}
+	if name, i, ok := c.kindCType(refType.Kind()); ok {
+		cStructs[structName] = append(
+			cStructs[structName],
+			structField{
+				_type:        name,
+				name:         c.cFieldName(field),
+				typeModifier: tMod,
+				offset:       field.Offset,
+				size:         field.Type.Size(),
+				align:        uintptr(field.Type.Align()),
+			},
+		)
+		if i != "" {
 			includes[i] = struct{}{}
 		}
 		return
@@ -265,47 +968,103 @@ func (c *CGoStructGen) generateCStructs(
 
 	switch refType.Kind() {
 	case reflect.Array:
+		// Arrays that are pointed to are represented as a pointer to their
+		// first element, so only arrays that are not behind a pointer add a
+		// dimension
+		if tMod.pntrs == 0 {
+			tMod.typeMod = TypeModArray
+			tMod.tModAmnts = append(slices.Clone(tMod.tModAmnts), refType.Len())
+		}
 		c.generateCStructs(
 			refType.Elem(), structName,
-			fieldName,
-			typeModifier{typeMod: TypeModArray, tModAmnt: refType.Len()},
+			field, tMod,
 			cStructs, includes,
 		)
 	case reflect.Pointer:
+		if tMod.typeMod == TypeModNone {
+			tMod.typeMod = TypeModPntr
+		}
+		tMod.pntrs++
 		c.generateCStructs(
 			refType.Elem(), structName,
-			fieldName, typeModifier{typeMod: TypeModPntr},
+			field, tMod,
 			cStructs, includes,
 		)
+	case reflect.Slice:
+		sliceName := c.generateSliceStruct(
+			refType, structName, field, cStructs, includes,
+		)
+		cStructs[structName] = append(
+			cStructs[structName],
+			structField{
+				_type:        c.cTypedef(sliceName),
+				name:         c.cFieldName(field),
+				typeModifier: tMod,
+				offset:       field.Offset,
+				size:         field.Type.Size(),
+				align:        uintptr(field.Type.Align()),
+				structRef:    sliceName,
+			},
+		)
 	case reflect.Struct:
-		newStructName := refType.Name()
-		if rename, ok := c.opts.StructRename[newStructName]; ok {
-			newStructName = rename
+		newStructName, inline, _ := c.cStructName(
+			refType, anonStructName(structName, c.cFieldName(field)),
+		)
+		if c.isOpaque(refType) {
+			// Opaque structs are only ever behind a pointer and have no
+			// fields to generate
+			cStructs[structName] = append(
+				cStructs[structName],
+				structField{
+					_type:        c.cTypedef(newStructName),
+					name:         c.cFieldName(field),
+					typeModifier: tMod,
+					offset:       field.Offset,
+					size:         field.Type.Size(),
+					align:        uintptr(field.Type.Align()),
+				},
+			)
+			return
+		}
+		if inline {
+			cStructs[structName] = append(
+				cStructs[structName],
+				structField{
+					name:         c.cFieldName(field),
+					typeModifier: tMod,
+					offset:       field.Offset,
+					size:         field.Type.Size(),
+					align:        c.cAlign(field.Type),
+					inline: c.generateStructFields(
+						refType, newStructName, cStructs, includes,
+					),
+				},
+			)
+			return
 		}
 		if structName != "" {
 			cStructs[structName] = append(
 				cStructs[structName],
 				structField{
-					_type:        fmt.Sprintf("%s_t", newStructName),
-					name:         fieldName,
+					_type:        c.cTypedef(newStructName),
+					name:         c.cFieldName(field),
 					typeModifier: tMod,
+					offset:       field.Offset,
+					size:         field.Type.Size(),
+					align:        c.cAlign(field.Type),
+					structRef:    newStructName,
 				},
 			)
 		}
 
-		if _, ok := cStructs[structName]; ok {
+		if len(cStructs[newStructName]) > 0 {
 			// If the struct fields were already populated then don't add them
 			// again
 			return
 		}
-		for i := range refType.NumField() {
-			iterField := refType.Field(i)
-			c.generateCStructs(
-				iterField.Type, newStructName,
-				iterField.Name, typeModifier{typeMod: TypeModNone},
-				cStructs, includes,
-			)
-		}
+		cStructs[newStructName] = c.generateStructFields(
+			refType, newStructName, cStructs, includes,
+		)
 	default:
 		// All errors should be caught by the [checkType] function
 		panic(fmt.Sprintf(
@@ -314,30 +1073,180 @@ func (c *CGoStructGen) generateCStructs(
 	}
 }
 
+// Regenerates the fields of every struct from the types that were passed to
+// [GenerateFor], in order of their C names. The fields of a struct can depend
+// on which struct first referred to it and on the enums that were added at the
+// time, so regenerating them keeps the output the same regardless of the order
+// types were added in. Nothing is done unless types were added since the last
+// time this was called, so all methods that read the structs call it first
+// rather than it being called each time a type is added.
+func (c *CGoStructGen) regenerate() {
+	if !c.stale {
+		return
+	}
+	c.stale = false
+	for name := range c.structs {
+		c.structs[name] = make([]structField, 0)
+	}
+	names := slices.Collect(maps.Keys(c.roots))
+	slices.Sort(names)
+	for _, name := range names {
+		c.generateCStructs(
+			c.roots[name], "",
+			reflect.StructField{}, typeModifier{typeMod: TypeModNone},
+			c.structs, c.includes,
+		)
+	}
+}
+
+// Returns the C fields of the supplied struct type. The fields are generated
+// under the supplied struct name, which must not already have any fields.
+func (c *CGoStructGen) generateStructFields(
+	refType reflect.Type, structName string,
+	cStructs map[string][]structField, includes map[include]struct{},
+) []structField {
+	c.appendStructFields(refType, structName, 0, cStructs, includes)
+	rv := padFields(cStructs[structName], 0, refType.Size())
+	delete(cStructs, structName)
+	return rv
+}
+
+// Appends the C fields of the supplied struct type to the fields of the C
+// struct with the supplied name. The base offset is added to the offset of
+// every field, which allows the fields of embedded structs to be flattened.
+func (c *CGoStructGen) appendStructFields(
+	refType reflect.Type, structName string, baseOffset uintptr,
+	cStructs map[string][]structField, includes map[include]struct{},
+) {
+	for i := range refType.NumField() {
+		iterField := refType.Field(i)
+		iterField.Offset += baseOffset
+		tag, _ := parseCgoTag(iterField)
+		if iterField.Type.Size() == 0 {
+			continue
+		}
+		if tag.skip || iterField.Name == "_" {
+			opaque := c.opaqueField(iterField)
+			opaque.reserved = iterField.Name == "_"
+			cStructs[structName] = append(cStructs[structName], opaque)
+			includes["<stdint.h>"] = struct{}{}
+			continue
+		}
+		switch c.embedMode(iterField, tag) {
+		case EmbeddedFieldsFlatten:
+			c.appendStructFields(
+				iterField.Type, structName, iterField.Offset,
+				cStructs, includes,
+			)
+			continue
+		case EmbeddedFieldsAnonymous:
+			// The promoted fields are generated as part of the parent so
+			// they are named the same way as flattened fields, they are
+			// then moved into an anonymous member
+			n := len(cStructs[structName])
+			c.appendStructFields(
+				iterField.Type, structName, iterField.Offset,
+				cStructs, includes,
+			)
+			inline := padFields(
+				slices.Clone(cStructs[structName][n:]),
+				iterField.Offset, iterField.Type.Size(),
+			)
+			cStructs[structName] = append(
+				cStructs[structName][:n],
+				structField{
+					typeModifier: typeModifier{typeMod: TypeModNone},
+					offset:       iterField.Offset,
+					size:         iterField.Type.Size(),
+					align:        c.cAlign(iterField.Type),
+					inline:       inline,
+				},
+			)
+			continue
+		}
+
+		n := len(cStructs[structName])
+		c.generateCStructs(
+			iterField.Type, structName,
+			iterField, typeModifier{typeMod: TypeModNone},
+			cStructs, includes,
+		)
+		// Each Go field becomes exactly one C member at index n
+		fields := cStructs[structName]
+		if c.opts.DocComments != DocCommentsNone {
+			fields[n].doc = c.docs[refType].fields[iterField.Name]
+		}
+		if tag._type != "" {
+			fields[n]._type = tag._type
+			fields[n].structRef = ""
+			fields[n].inline = nil
+		}
+		if c.opts.EmitArrayLenMacros {
+			fields[n].lenMacros = c.arrayLenMacros(
+				structName, c.cFieldName(iterField), arrayDims(iterField.Type),
+				c.arrayLenConsts(refType, iterField),
+			)
+		}
+	}
+}
+
 // Writes all of the struct definitions that were previously added through calls
-// to [GenerateFor] to the specified file.
+// to [GenerateFor] to the specified file. It is safe to call this method while
+// other goroutines are adding types.
 func (c *CGoStructGen) WriteTo(file string, headerStr string) error {
-	var err error
-	var f *os.File
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regenerate()
 
-	f, err = os.Create(file)
-	defer f.Close()
+	err := c.writeHeader(file, headerStr, nil)
+	if err != nil && c.opts.ExitOnErr {
+		log.Fatal(err)
+	}
+	return err
+}
+
+// Writes the parts of the generated code that belong to the supplied group to
+// the specified file. A nil group writes everything.
+func (c *CGoStructGen) writeHeader(
+	file string,
+	headerStr string,
+	g *headerGroup,
+) error {
+	if c.opts.EmitLayoutHashes && g.isCommon() {
+		if err := c.checkHeaderHashMacro(headerStr); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(file)
 	if err != nil {
-		goto errExit
+		return err
 	}
+	defer f.Close()
 
 	c.templateHeader(f, headerStr)
-	c.templateIncludes(f)
+	c.templateIncludes(f, g)
 	c.templateExternCIf(f, func() {
-		c.templateCStructs(f)
+		if g.isCommon() {
+			c.templateConsts(f)
+			c.templateGoInts(f)
+		}
+		c.templateHandles(f, g)
+		if g.isCommon() {
+			c.templateComplexPairs(f)
+		}
+		c.templateCTypedefs(f, g)
+		c.templateCEnums(f, g)
+		c.templateOpaques(f, g)
+		c.templateCStructs(f, g)
+		if g.isCommon() {
+			c.templateStaticAsserts(f)
+		}
+		if c.opts.EmitLayoutHashes {
+			c.templateLayoutHashes(f, headerStr, g)
+		}
 	})
 	c.templateFooter(f)
-
-errExit:
-	if err != nil && c.opts.ExitOnErr {
-		log.Fatal(err)
-	}
-	return err
+	return nil
 }
 
 func (c *CGoStructGen) templateHeader(f *os.File, headerStr string) {
@@ -349,6 +1258,9 @@ func (c *CGoStructGen) templateHeader(f *os.File, headerStr string) {
 	f.WriteString("\n\n")
 	f.WriteString("// File generated by cgoStructGen - DO NOT EDIT\n")
 	f.WriteString("// Struct definitions generated for C from Go struct definitions\n")
+	if c.opts.PODOnly {
+		f.WriteString("// All structs are position-independent plain data, they contain no pointers\n")
+	}
 	f.WriteString("\n")
 }
 
@@ -364,7 +1276,14 @@ func (c *CGoStructGen) templateExternCIf(f *os.File, op func()) {
 	f.WriteString("#endif\n\n")
 }
 
-func (c *CGoStructGen) templateIncludes(f *os.File) {
+func (c *CGoStructGen) templateIncludes(f *os.File, g *headerGroup) {
+	if !g.isCommon() {
+		for _, inc := range g.includes {
+			fmt.Fprintf(f, "#include %q\n", inc)
+		}
+		f.WriteString("\n")
+		return
+	}
 	includes := slices.Collect(maps.Keys(c.includes))
 	slices.Sort(includes)
 	for _, inc := range includes {
@@ -374,22 +1293,108 @@ func (c *CGoStructGen) templateIncludes(f *os.File) {
 	f.WriteString("\n")
 }
 
-func (c *CGoStructGen) templateCStructs(f *os.File) {
+// Returns the names of all structs ordered such that every struct comes after
+// the structs it refers to. Structs that do not depend on each other are
+// sorted by name.
+func (c *CGoStructGen) sortedStructNames() []string {
 	structNames := slices.Collect(maps.Keys(c.structs))
 	slices.Sort(structNames)
+
+	rv := make([]string, 0, len(structNames))
+	visited := map[string]struct{}{}
+	var visit func(name string)
+	visit = func(name string) {
+		if _, ok := visited[name]; ok {
+			return
+		}
+		visited[name] = struct{}{}
+		for _, iterField := range c.structs[name] {
+			if _, ok := c.structs[iterField.structRef]; ok {
+				visit(iterField.structRef)
+			}
+		}
+		rv = append(rv, name)
+	}
 	for _, structName := range structNames {
+		visit(structName)
+	}
+	return rv
+}
+
+func (c *CGoStructGen) templateCStructs(f *os.File, g *headerGroup) {
+	lenMacros := map[string]struct{}{}
+	for _, structName := range c.sortedStructNames() {
+		if !g.has(structName) {
+			continue
+		}
 		structFields := c.structs[structName]
+		templateLenMacros(f, structFields, lenMacros)
+		c.templateStructDoc(f, structName)
 		f.WriteString("\ttypedef struct ")
-		f.WriteString(structName)
+		f.WriteString(c.cTag(structName))
 		f.WriteString("{\n")
-		for _, iterField := range structFields {
-			f.WriteString("\t\t")
+		c.templateFields(f, structFields, "\t\t")
+		f.WriteString("\t} ")
+		f.WriteString(c.cTypedef(structName))
+		f.WriteString(";\n\n")
+	}
+}
+
+// Writes the array length macros of the supplied fields. Macros that are shared
+// by several fields, see [Opts.ArrayLenConstNames], are only written once.
+func templateLenMacros(
+	f *os.File,
+	structFields []structField,
+	written map[string]struct{},
+) {
+	for _, iterField := range structFields {
+		templateLenMacros(f, iterField.inline, written)
+		for i, macro := range iterField.lenMacros {
+			if _, ok := written[macro]; ok {
+				continue
+			}
+			written[macro] = struct{}{}
+			fmt.Fprintf(
+				f, "\t#define %s %d\n", macro, iterField.tModAmnts[i],
+			)
+		}
+	}
+}
+
+func (c *CGoStructGen) templateFields(
+	f *os.File,
+	structFields []structField,
+	indent string,
+) {
+	for _, iterField := range structFields {
+		doc := docLines(iterField.doc)
+		trailing := c.opts.DocComments == DocCommentsDoxygen &&
+			len(doc) == 1 && iterField.inline == nil
+		if !trailing {
+			c.templateDoc(f, indent, doc)
+		}
+		f.WriteString(indent)
+		if iterField.inline == nil {
 			f.WriteString(iterField.String())
-			f.WriteString(";\n")
+			f.WriteString(";")
+			if trailing {
+				fmt.Fprintf(f, " ///< %s", doc[0])
+			}
+			f.WriteString("\n")
+			continue
 		}
-		f.WriteString("\t} ")
-		f.WriteString(structName)
-		f.WriteString("_t;\n\n")
+		f.WriteString("struct{\n")
+		c.templateFields(f, iterField.inline, indent+"\t")
+		if iterField.name == "" {
+			// An anonymous member
+			fmt.Fprintf(f, "%s};\n", indent)
+			continue
+		}
+		fmt.Fprintf(
+			f, "%s}%s %s%s;\n",
+			indent, strings.Repeat("*", iterField.pntrs), iterField.name,
+			iterField.dimsString(true),
+		)
 	}
 }
 
@@ -3,12 +3,22 @@ package sbcgostructgen
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"unsafe"
 
 	sbtest "github.com/barbell-math/smoothbrain-test"
 )
 
+func structFieldsMatch(t *testing.T, expected []structField, got []structField) {
+	t.Helper()
+	sbtest.EqFunc(t, expected, got, func(l []structField, r []structField) bool {
+		return reflect.DeepEqual(l, r)
+	})
+}
+
 func TestGenerateForNonStruct(t *testing.T) {
 	err := GenerateFor[int](New(Opts{}))
 	sbtest.ContainsError(t, InvalidTypeErr, err)
@@ -55,13 +65,13 @@ func TestGenerateForStructWithInterface(t *testing.T) {
 func TestGenerateForStructWithComplex64(t *testing.T) {
 	type s1 struct{ f1 complex64 }
 	err := GenerateFor[s1](New(Opts{}))
-	sbtest.ContainsError(t, InvalidTypeErr, err)
+	sbtest.Nil(t, err)
 }
 
 func TestGenerateForStructWithComplex128(t *testing.T) {
 	type s1 struct{ f1 complex128 }
 	err := GenerateFor[s1](New(Opts{}))
-	sbtest.ContainsError(t, InvalidTypeErr, err)
+	sbtest.Nil(t, err)
 }
 
 func TestGenerateForStructWithInt(t *testing.T) {
@@ -163,6 +173,39 @@ func TestGenerateForStructOfStruct(t *testing.T) {
 	sbtest.ContainsError(t, InvalidTypeErr, err)
 }
 
+func TestGenerateForNestedStructPopulated(t *testing.T) {
+	type s2 struct {
+		f1 int8
+		f2 int32
+	}
+	type s1 struct {
+		f1 int64
+		f2 s2
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, 2, len(res.structs))
+	structFieldsMatch(t, res.structs["s2"],
+		[]structField{
+			{_type: "int8_t", name: "f1", offset: 0, size: 1, align: 1},
+			{_type: "int32_t", name: "f2", offset: 4, size: 4, align: 4},
+		},
+	)
+	structFieldsMatch(t, res.structs["s1"],
+		[]structField{
+			{_type: "int64_t", name: "f1", offset: 0, size: 8, align: 8},
+			{
+				_type: "s2_t", name: "f2",
+				offset: 8, size: 8, align: 4,
+				structRef: "s2",
+			},
+		},
+	)
+}
+
 func TestGenerateForEmbededField(t *testing.T) {
 	type s2 struct{ f2 int32 }
 	type s1 struct{ s2 }
@@ -181,12 +224,15 @@ func TestGenerateForSimpleStruct(t *testing.T) {
 		res.includes,
 	)
 	sbtest.Eq(t, 1, len(res.structs))
-	sbtest.SlicesMatch(t, res.structs["s1"],
+	structFieldsMatch(t, res.structs["s1"],
 		[]structField{
 			{
-				typeModifier: typeModifier{typeMod: TypeModNone, tModAmnt: 0},
+				typeModifier: typeModifier{typeMod: TypeModNone},
 				_type:        "int8_t",
 				name:         "f1",
+				offset:       0,
+				size:         1,
+				align:        1,
 			},
 		},
 	)
@@ -467,3 +513,269 @@ extern "C" {
 `
 	sbtest.Eq(t, string(data), exp)
 }
+
+func TestGenerateForPODOnly(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		f2 [4]uint32
+		f3 float64
+		f4 bool
+	}
+	err := GenerateFor[s1](New(Opts{PODOnly: true}))
+	sbtest.Nil(t, err)
+
+	type s2 struct{ f1 string }
+	err = GenerateFor[s2](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s3 struct{ f1 *int32 }
+	err = GenerateFor[s3](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s4 struct{ f1 unsafe.Pointer }
+	err = GenerateFor[s4](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s5 struct{ f1 uintptr }
+	err = GenerateFor[s5](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s6 struct{ f1 [2]*int32 }
+	err = GenerateFor[s6](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+}
+
+func TestGenerateForPODOnlyFieldPath(t *testing.T) {
+	type s3 struct{ f3 string }
+	type s2 struct{ f2 [2]s3 }
+	type s1 struct {
+		f0 int32
+		f1 s2
+	}
+	err := GenerateFor[s1](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+	sbtest.True(t, strings.HasSuffix(err.Error(), "field f1.f2.f3"))
+}
+
+func TestGenerateForPODOnlySkippedFields(t *testing.T) {
+	type s1 struct {
+		A int32
+		P *int32 `cgo:"-"`
+	}
+	err := GenerateFor[s1](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+	sbtest.True(t, strings.HasSuffix(err.Error(), "field P"))
+
+	type s3 struct{ f1 map[int]int }
+	type s2 struct {
+		A int32
+		B [2]s3 `cgo:"-"`
+	}
+	err = GenerateFor[s2](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s4 struct {
+		A int32
+		B [2]int64 `cgo:"-"`
+	}
+	sbtest.Nil(t, GenerateFor[s4](New(Opts{PODOnly: true})))
+}
+
+func TestGenerateForPODOnlyBlankFields(t *testing.T) {
+	type s1 struct {
+		A int32
+		_ string
+	}
+	err := GenerateFor[s1](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s2 struct {
+		A int32
+		_ [2]*int32
+	}
+	err = GenerateFor[s2](New(Opts{PODOnly: true}))
+	sbtest.ContainsError(t, NonPODTypeErr, err)
+
+	type s3 struct {
+		A int32
+		_ [4]uint8
+	}
+	sbtest.Nil(t, GenerateFor[s3](New(Opts{PODOnly: true})))
+}
+
+func TestWritePODOnly(t *testing.T) {
+	type s1 struct{ f1 int8 }
+	res := New(Opts{PODOnly: true})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/podOnly.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/podOnly.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+// All structs are position-independent plain data, they contain no pointers
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t f1;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestGenerateForArrayModifiers(t *testing.T) {
+	type s1 struct {
+		f1 [2][3]int32
+		f2 [2]*int32
+		f3 *[4]int32
+		f4 **int32
+		f5 [2]*[3]int32
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 5, len(fields))
+	sbtest.Eq(t, "int32_t f1[2][3]", fields[0].String())
+	sbtest.Eq(t, "int32_t* f2[2]", fields[1].String())
+	sbtest.Eq(t, "int32_t* f3", fields[2].String())
+	sbtest.Eq(t, "int32_t** f4", fields[3].String())
+	sbtest.Eq(t, "int32_t* f5[2]", fields[4].String())
+	sbtest.Eq(t, "int32_t[2][3]", fields[0].typeString())
+	sbtest.Eq(t, "int32_t*[2]", fields[1].typeString())
+}
+
+type (
+	testConcurrentInner struct {
+		f1 int32
+		f2 testColor
+	}
+	testConcurrentA struct {
+		f1 testConcurrentInner
+		f2 []testConcurrentInner
+		f3 struct{ f1 int64 }
+	}
+	testConcurrentB struct {
+		f1 *testConcurrentInner
+		f2 []int16
+		f3 testColor
+	}
+	testConcurrentC struct {
+		f1 [2]testConcurrentInner
+		f2 []testColor
+	}
+)
+
+func TestGenerateForConcurrent(t *testing.T) {
+	opts := Opts{
+		SliceStructs:     true,
+		AnonymousStructs: AnonymousStructsNamed,
+		EmitLayoutHashes: true,
+	}
+	steps := []func(c *CGoStructGen) error{
+		func(c *CGoStructGen) error { return GenerateFor[testConcurrentA](c) },
+		func(c *CGoStructGen) error { return GenerateFor[testConcurrentB](c) },
+		func(c *CGoStructGen) error { return GenerateFor[testConcurrentC](c) },
+		func(c *CGoStructGen) error {
+			return GenerateEnum(c, testColorRed, testColorGreen, testColorBlue)
+		},
+		func(c *CGoStructGen) error { return GenerateConst(c, "MAX", int32(5)) },
+	}
+
+	exp := New(opts)
+	for _, step := range steps {
+		sbtest.Nil(t, step(exp))
+	}
+	err := exp.WriteTo("./bs/testData/concurrentExp.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+	expData, err := os.ReadFile("./bs/testData/concurrentExp.h")
+	sbtest.Nil(t, err)
+
+	// Adding the types in reverse order gives the same header
+	res := New(opts)
+	for i := len(steps) - 1; i >= 0; i-- {
+		sbtest.Nil(t, steps[i](res))
+	}
+	err = res.WriteTo("./bs/testData/concurrent.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+	data, err := os.ReadFile("./bs/testData/concurrent.h")
+	sbtest.Nil(t, err)
+	sbtest.Eq(t, string(expData), string(data))
+
+	for range 10 {
+		res := New(opts)
+		var wg sync.WaitGroup
+		errs := make(chan error, 4*len(steps))
+		for range 4 {
+			for _, step := range steps {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- step(res)
+				}()
+			}
+		}
+		// Readers run while types are still being added
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.Layout()
+			res.HeaderLayoutHash()
+			res.PaddingReport()
+			res.PointerRuleReport()
+		}()
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			sbtest.Nil(t, err)
+		}
+
+		err = res.WriteTo("./bs/testData/concurrent.h", "HEADER_GUARD")
+		sbtest.Nil(t, err)
+		data, err := os.ReadFile("./bs/testData/concurrent.h")
+		sbtest.Nil(t, err)
+		sbtest.Eq(t, string(expData), string(data))
+	}
+}
+
+func TestGenerateForErrorRollback(t *testing.T) {
+	type inner struct{ f1 testColor }
+	type s1 struct {
+		f1 inner
+		f2 [2]int32
+		f3 map[int]int
+	}
+	type s2 struct{ f1 int32 }
+	res := New(Opts{EmitArrayLenMacros: true})
+	sbtest.Nil(t, GenerateFor[s2](res))
+	sbtest.ContainsError(t, InvalidTypeErr, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	sbtest.Eq(t, 1, len(res.roots))
+	sbtest.Eq(t, 0, len(res.typedefs))
+	sbtest.Eq(t, 0, len(res.macros))
+	sbtest.Eq(t, 2, len(res.typeNames))
+
+	// The names that were claimed by the failed call can still be used
+	sbtest.Nil(t, GenerateConst(res, "S1_F2_LEN", int32(2)))
+	sbtest.Nil(t, GenerateEnum(res, testColorRed))
+	{
+		type inner struct{ f1 int8 }
+		sbtest.Nil(t, GenerateFor[inner](res))
+	}
+}
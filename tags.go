@@ -0,0 +1,159 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+type (
+	// The parsed value of a `cgo` struct tag.
+	cgoTag struct {
+		skip   bool
+		name   string
+		_type  string
+		embed  string
+		opaque bool
+	}
+)
+
+const cgoTagKey = "cgo"
+
+var (
+	cIdentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	cTypeRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ]*\**$`)
+
+	paddingTypes = map[uintptr]fieldType{
+		1: FieldTypeUint8T,
+		2: FieldTypeUint16T,
+		4: FieldTypeUint32T,
+		8: FieldTypeUint64T,
+	}
+)
+
+// Parses the `cgo` tag on the supplied struct field. The tag is a comma
+// separated list of options:
+//   - `-`: skip the field, it must be the only option
+//   - `name=<ident>`: the name of the field in C
+//   - `type=<C type>`: the C type of the field, replacing the element type for
+//     arrays and pointers, its layout is checked like a [TypeOverride]
+//   - `embed=<mode>`: how an embedded struct is written, one of `nested`,
+//     `anonymous`, or `flatten` (see [Opts.EmbeddedFields])
+//   - `opaque=<bool>`: if true the struct the field points to is written as an
+//     opaque type (see [Opts.OpaqueStructs])
+func parseCgoTag(field reflect.StructField) (cgoTag, error) {
+	var rv cgoTag
+	tag, ok := field.Tag.Lookup(cgoTagKey)
+	if !ok {
+		return rv, nil
+	}
+	if tag == "-" {
+		rv.skip = true
+		return rv, nil
+	}
+
+	for _, opt := range strings.Split(tag, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(opt), "=")
+		if !found {
+			return rv, sberr.Wrap(
+				InvalidTagErr, "Expected key=value or -, got '%s'", opt,
+			)
+		}
+		val = strings.TrimSpace(val)
+		switch key {
+		case "name":
+			if rv.name != "" {
+				return rv, sberr.Wrap(InvalidTagErr, "Duplicate name option")
+			}
+			if !cIdentRegex.MatchString(val) {
+				return rv, sberr.Wrap(
+					InvalidTagErr, "'%s' is not a valid C identifier", val,
+				)
+			}
+			rv.name = val
+		case "type":
+			if rv._type != "" {
+				return rv, sberr.Wrap(InvalidTagErr, "Duplicate type option")
+			}
+			if !cTypeRegex.MatchString(val) {
+				return rv, sberr.Wrap(
+					InvalidTagErr, "'%s' is not a valid C type", val,
+				)
+			}
+			rv._type = val
+		case "embed":
+			if rv.embed != "" {
+				return rv, sberr.Wrap(InvalidTagErr, "Duplicate embed option")
+			}
+			if _, ok := embedTagModes[val]; !ok {
+				return rv, sberr.Wrap(
+					InvalidTagErr, "'%s' is not a valid embed mode", val,
+				)
+			}
+			rv.embed = val
+		case "opaque":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return rv, sberr.Wrap(
+					InvalidTagErr, "'%s' is not a valid bool", val,
+				)
+			}
+			rv.opaque = b
+		default:
+			return rv, sberr.Wrap(InvalidTagErr, "Unknown option '%s'", key)
+		}
+	}
+	return rv, nil
+}
+
+// Checks the C type set with the `type` option of the supplied tag against the
+// Go type it replaces, which is the element type for arrays and pointers. The
+// C type is checked the same way as a [TypeOverride], so its size is either
+// checked now or verified with static asserts in the generated header.
+func (c *CGoStructGen) checkTagType(refType reflect.Type, tag cgoTag) error {
+	if tag._type == "" {
+		return nil
+	}
+	for refType.Kind() == reflect.Array || refType.Kind() == reflect.Pointer {
+		refType = refType.Elem()
+	}
+	return c.checkTypeOverride(refType, TypeOverride{Name: tag._type})
+}
+
+// Returns the name the field will have in C, accounting for any `cgo` tag and
+// [Opts.IdentifierEscaping]. Blank fields are named after their offset so that
+// each one is unique. The tag is assumed to have already been validated.
+func (c *CGoStructGen) cFieldName(field reflect.StructField) string {
+	tag, _ := parseCgoTag(field)
+	if field.Name == "_" && tag.name == "" {
+		return reservedName(field.Offset)
+	}
+	if tag.skip {
+		return c.cIdent(fmt.Sprintf("_reserved_%s", c.fieldCase(field.Name)))
+	}
+	if tag.name != "" {
+		return c.cIdent(tag.name)
+	}
+	return c.cIdent(c.fieldCase(field.Name))
+}
+
+// Returns an opaque field that takes up the same space, and has the same
+// alignment, as the supplied field.
+func (c *CGoStructGen) opaqueField(field reflect.StructField) structField {
+	align := uintptr(field.Type.Align())
+	return structField{
+		_type: paddingTypes[align].String(),
+		name:  c.cFieldName(field),
+		typeModifier: typeModifier{
+			typeMod:   TypeModArray,
+			tModAmnts: []int{int(field.Type.Size() / align)},
+		},
+		offset: field.Offset,
+		size:   field.Type.Size(),
+		align:  align,
+	}
+}
@@ -0,0 +1,362 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestParseCgoTag(t *testing.T) {
+	type s1 struct {
+		f1 int32
+		f2 int32 `cgo:"-"`
+		f3 int32 `cgo:"name=foo"`
+		f4 int32 `cgo:"type=uint32_t"`
+		f5 int32 `cgo:"name=bar, type=unsigned int"`
+		f6 int32 `other:"f6"`
+	}
+	exp := []cgoTag{
+		{},
+		{skip: true},
+		{name: "foo"},
+		{_type: "uint32_t"},
+		{name: "bar", _type: "unsigned int"},
+		{},
+	}
+	for i, e := range exp {
+		tag, err := parseCgoTag(reflect.TypeFor[s1]().Field(i))
+		sbtest.Nil(t, err)
+		sbtest.Eq(t, e, tag)
+	}
+}
+
+func TestParseCgoTagInvalid(t *testing.T) {
+	type s1 struct {
+		f1 int32 `cgo:"name"`
+		f2 int32 `cgo:"-,name=foo"`
+		f3 int32 `cgo:"name=1foo"`
+		f4 int32 `cgo:"type=int32_t;"`
+		f5 int32 `cgo:"name=foo,name=bar"`
+		f6 int32 `cgo:"type=int32_t,type=int32_t"`
+		f7 int32 `cgo:"align=4"`
+		f8 int32 `cgo:""`
+	}
+	for i := range 8 {
+		_, err := parseCgoTag(reflect.TypeFor[s1]().Field(i))
+		sbtest.ContainsError(t, InvalidTagErr, err)
+	}
+}
+
+func TestGenerateForInvalidTag(t *testing.T) {
+	type s2 struct {
+		f1 int32 `cgo:"bad"`
+	}
+	type s1 struct {
+		f1 int32
+		f2 s2
+	}
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTagErr, err)
+	sbtest.True(t, strings.HasSuffix(err.Error(), "field f2.f1"))
+}
+
+func TestGenerateForTagDuplicateName(t *testing.T) {
+	type s1 struct {
+		f1 int32
+		f2 int32 `cgo:"name=f1"`
+	}
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
+
+func TestGenerateForTagSkipInvalidType(t *testing.T) {
+	type s1 struct {
+		f1 int32
+		f2 map[string]int `cgo:"-"`
+		f3 int32
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	structFieldsMatch(t,
+		[]structField{
+			{_type: "int32_t", name: "f1", offset: 0, size: 4, align: 4},
+			{
+				_type: "uint64_t", name: "_reserved_f2",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{1}},
+				offset:       8, size: 8, align: 8,
+			},
+			{_type: "int32_t", name: "f3", offset: 16, size: 4, align: 4},
+		},
+		res.structs["s1"],
+	)
+}
+
+func TestGenerateForTagTypeOverride(t *testing.T) {
+	type s2 struct{ f1 int32 }
+	type s1 struct {
+		f1 [4]uint8 `cgo:"type=char"`
+		f2 s2       `cgo:"type=other_t,name=other"`
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	structFieldsMatch(t,
+		[]structField{
+			{
+				_type: "char", name: "f1",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{4}},
+				offset:       0, size: 4, align: 1,
+			},
+			{_type: "other_t", name: "other", offset: 4, size: 4, align: 4},
+		},
+		res.structs["s1"],
+	)
+}
+
+func TestGenerateForTagTypeSizeMismatch(t *testing.T) {
+	type s1 struct {
+		A int8 `cgo:"type=int64_t"`
+		B int8
+	}
+	err := GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, LayoutMismatchErr, err)
+
+	type s2 struct {
+		A [2]*int32 `cgo:"type=int64_t"`
+	}
+	err = GenerateFor[s2](New(Opts{}))
+	sbtest.ContainsError(t, LayoutMismatchErr, err)
+
+	// Unknown C types are verified by the compiler
+	type s3 struct {
+		A [2]uint16 `cgo:"type=my_short"`
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s3](res))
+	sbtest.Eq(t, 2, len(res.staticAsserts()))
+}
+
+func TestGenerateForTagTypeSliceField(t *testing.T) {
+	type s1 struct {
+		f1 []int32 `cgo:"type=ids_t"`
+		f2 [2]int32
+		f3 [3]int32 `cgo:"type=int32_t"`
+	}
+	res := New(Opts{SliceStructs: true, EmitArrayLenMacros: true})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 3, len(fields))
+	sbtest.Eq(t, "ids_t f1", fields[0].String())
+	sbtest.Eq(t, "", fields[0].structRef)
+	sbtest.Eq(t, "int32_t f2[S1_F2_LEN]", fields[1].String())
+	sbtest.Eq(t, "int32_t f3[S1_F3_LEN]", fields[2].String())
+}
+
+func TestWriteTaggedStruct(t *testing.T) {
+	type s1 struct {
+		f1 int8   `cgo:"name=first"`
+		f2 string `cgo:"-"`
+		f3 int16  `cgo:"-"`
+		f4 uint32 `cgo:"type=unsigned int"`
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/taggedStruct.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/taggedStruct.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <assert.h>
+#include <stdalign.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t first;
+		uint64_t _reserved_f2[2];
+		uint16_t _reserved_f3[1];
+		unsigned int f4;
+	} s1_t;
+
+	static_assert(sizeof(unsigned int) == 4, "unsigned int must have the same size as the Go type uint32");
+	static_assert(alignof(unsigned int) == 4, "unsigned int must have the same alignment as the Go type uint32");
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestGenerateForBlankFields(t *testing.T) {
+	type s1 struct {
+		f1 int8
+		_  [3]uint8
+		f2 int32
+		_  int64
+		_  [0]func()
+		_  string `cgo:"name=pad"`
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 5, len(fields))
+	sbtest.Eq(t, "uint8_t _reserved_1[3]", fields[1].String())
+	sbtest.Eq(t, "uint64_t _reserved_8[1]", fields[3].String())
+	sbtest.Eq(t, "uint64_t pad[2]", fields[4].String())
+}
+
+func TestGenerateForBlankFieldDuplicateName(t *testing.T) {
+	type s1 struct {
+		f1          int32
+		_           int32
+		_reserved_4 int32
+	}
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](New(Opts{})))
+}
+
+func TestGenerateForZeroSizeFields(t *testing.T) {
+	type empty struct{}
+	type s1 struct {
+		f1 int32
+		f2 empty
+		f3 [0]int64
+		f4 int8
+		f5 struct{}
+	}
+	res := New(Opts{AnonymousStructs: AnonymousStructsNamed})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	sbtest.Eq(t, 1, len(res.structs))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 4, len(fields))
+	sbtest.Eq(t, "int32_t f1", fields[0].String())
+	// The dropped [0]int64 still aligns the following field in Go
+	sbtest.Eq(t, "uint8_t _reserved_4[4]", fields[1].String())
+	sbtest.Eq(t, "int8_t f4", fields[2].String())
+	// Go adds padding after a final zero size field
+	sbtest.Eq(t, 16, reflect.TypeFor[s1]().Size())
+	sbtest.Eq(t, "uint8_t _reserved_9[7]", fields[3].String())
+	sbtest.Eq(t, 9, fields[3].offset)
+}
+
+func TestGenerateForZeroSizeReferenced(t *testing.T) {
+	type empty struct{}
+	err := GenerateFor[empty](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s1 struct{ f1 *empty }
+	err = GenerateFor[s1](New(Opts{}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+
+	type s2 struct{ f1 []empty }
+	err = GenerateFor[s2](New(Opts{SliceStructs: true}))
+	sbtest.ContainsError(t, InvalidTypeErr, err)
+}
+
+func TestGenerateForZeroSizeEmbedded(t *testing.T) {
+	type Base struct {
+		ID  int32
+		End struct{}
+	}
+	type s1 struct {
+		Base
+		f1 int32
+	}
+	res := New(Opts{EmbeddedFields: EmbeddedFieldsAnonymous})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 2, len(fields))
+	sbtest.Eq(t,
+		"struct{ int32_t ID; uint8_t _reserved_4[4]; }", fields[0].String(),
+	)
+	sbtest.Eq(t, 8, fields[1].offset)
+}
+
+func TestWriteBlankAndZeroSizeFields(t *testing.T) {
+	type s1 struct {
+		_  [0]func()
+		f1 int8
+		_  [3]uint8
+		f2 int32
+		_  uint32
+		f3 int64
+		f4 struct{}
+	}
+	res := New(Opts{})
+	err := GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/blankFields.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/blankFields.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef struct s1{
+		int8_t f1;
+		uint8_t _reserved_1[3];
+		int32_t f2;
+		uint32_t _reserved_8[1];
+		int64_t f3;
+		uint8_t _reserved_24[8];
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}
+
+func TestGenerateForZeroSizeAlignment(t *testing.T) {
+	type inner struct {
+		_  [0]int64
+		f1 int32
+	}
+	type s1 struct {
+		f1 int32
+		f2 inner
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	// Without the [0]int64 inner only has an alignment of 4 in C, so the
+	// offset Go gives it has to be kept with explicit padding
+	sbtest.Eq(t, 4, res.cAlign(reflect.TypeFor[inner]()))
+	fields := res.structs["s1"]
+	sbtest.Eq(t, 3, len(fields))
+	sbtest.Eq(t, "uint8_t _reserved_4[4]", fields[1].String())
+	sbtest.Eq(t, "inner_t f2", fields[2].String())
+	sbtest.Eq(t, 8, fields[2].offset)
+	innerFields := res.structs["inner"]
+	sbtest.Eq(t, 2, len(innerFields))
+	sbtest.Eq(t, "uint8_t _reserved_4[4]", innerFields[1].String())
+}
@@ -0,0 +1,83 @@
+package sbcgostructgen
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+
+	sberr "github.com/barbell-math/smoothbrain-errs"
+)
+
+// Returns the C name of the typedef for the supplied type and true if the type
+// is a named, non-struct type that should be represented by a typedef in C.
+// Builtin types such as int32 are not named in this sense, nor is
+// unsafe.Pointer.
+func (c *CGoStructGen) typedefName(refType reflect.Type) (string, bool) {
+	if refType.Name() == "" || refType.PkgPath() == "" ||
+		refType.PkgPath() == "unsafe" {
+		return "", false
+	}
+	if _, _, ok := c.kindCType(refType.Kind()); !ok {
+		return "", false
+	}
+	return c.typedefRename(refType.Name(), false), true
+}
+
+// Returns the name of the typedef for the supplied Go type name, taking
+// [Opts.TypedefRename] and [Opts.Naming] into account. If tagged is true the
+// type is also written with a tag, as is the case for enums.
+func (c *CGoStructGen) typedefRename(name string, tagged bool) string {
+	if rename, ok := c.opts.TypedefRename[name]; ok {
+		name = rename
+	}
+	return c.cTypeIdent(c.typeCase(name), tagged)
+}
+
+// Checks that the supplied typedef name is not already used by a different Go
+// type.
+func (c *CGoStructGen) checkTypedefName(refType reflect.Type, name string) error {
+	if err := c.checkTypeIdent(name, false); err != nil {
+		return err
+	}
+	if err := c.claimTypeNames(refType, c.cTypedef(name)); err != nil {
+		return err
+	}
+	if other, ok := c.typedefs[name]; ok && other != refType {
+		return sberr.Wrap(
+			DuplicateNameErr, "The C typedef %s is used by both %s and %s",
+			name, other, refType,
+		)
+	}
+	for otherType, enum := range c.enums {
+		if enum.name == name && otherType != refType {
+			return sberr.Wrap(
+				DuplicateNameErr, "The C typedef %s is used by both %s and %s",
+				name, otherType, refType,
+			)
+		}
+	}
+	return nil
+}
+
+func (c *CGoStructGen) templateCTypedefs(f *os.File, g *headerGroup) {
+	names := slices.Collect(maps.Keys(c.typedefs))
+	slices.Sort(names)
+	cntr := 0
+	for _, name := range names {
+		if !g.has(name) {
+			continue
+		}
+		if _, ok := c.enums[c.typedefs[name]]; ok {
+			// Enums write their own typedef
+			continue
+		}
+		cType, _, _ := c.kindCType(c.typedefs[name].Kind())
+		fmt.Fprintf(f, "\ttypedef %s %s;\n", cType, c.cTypedef(name))
+		cntr++
+	}
+	if cntr > 0 {
+		f.WriteString("\n")
+	}
+}
@@ -0,0 +1,129 @@
+package sbcgostructgen
+
+import (
+	"os"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGenerateForTypedefField(t *testing.T) {
+	type meters float64
+	type userID uint64
+	type s1 struct {
+		f1 meters
+		f2 [2]userID
+		f3 *meters
+		f4 float64
+	}
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	structFieldsMatch(t,
+		[]structField{
+			{_type: "meters_t", name: "f1", offset: 0, size: 8, align: 8},
+			{
+				_type: "userID_t", name: "f2",
+				typeModifier: typeModifier{typeMod: TypeModArray, tModAmnts: []int{2}},
+				offset:       8, size: 16, align: 8,
+			},
+			{
+				_type: "meters_t", name: "f3",
+				typeModifier: typeModifier{typeMod: TypeModPntr, pntrs: 1},
+				offset:       24, size: 8, align: 8,
+			},
+			{_type: "double_t", name: "f4", offset: 32, size: 8, align: 8},
+		},
+		res.structs["s1"],
+	)
+	sbtest.Eq(t, 2, len(res.typedefs))
+}
+
+func TestGenerateForTypedefCollision(t *testing.T) {
+	type meters float64
+	type s1 struct{ f1 meters }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+
+	err := func() error {
+		type meters float32
+		type s2 struct{ f1 meters }
+		return GenerateFor[s2](res)
+	}()
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
+
+func TestGenerateForTypedefRenameCollision(t *testing.T) {
+	type meters float64
+	type feet float64
+	type s1 struct {
+		f1 meters
+		f2 feet
+	}
+	res := New(Opts{TypedefRename: map[string]string{"feet": "meters"}})
+	sbtest.ContainsError(t, DuplicateNameErr, GenerateFor[s1](res))
+}
+
+func TestGenerateEnumTypedefCollision(t *testing.T) {
+	type testColor int8
+	type s1 struct{ f1 testColor }
+	res := New(Opts{})
+	sbtest.Nil(t, GenerateFor[s1](res))
+	err := GenerateEnum(res, testColorRed)
+	sbtest.ContainsError(t, DuplicateNameErr, err)
+}
+
+func TestWriteTypedefs(t *testing.T) {
+	type meters float64
+	type userID uint64
+	type s1 struct {
+		f1 meters
+		f2 userID
+		f3 testColor
+	}
+	res := New(Opts{
+		TypedefRename: map[string]string{"userID": "user_id", "testColor": "color"},
+	})
+	err := GenerateEnum(res, testColorRed)
+	sbtest.Nil(t, err)
+	err = GenerateFor[s1](res)
+	sbtest.Nil(t, err)
+	err = res.WriteTo("./bs/testData/typedefs.h", "HEADER_GUARD")
+	sbtest.Nil(t, err)
+
+	data, err := os.ReadFile("./bs/testData/typedefs.h")
+	sbtest.Nil(t, err)
+	exp := `#ifndef HEADER_GUARD
+#define HEADER_GUARD
+
+// File generated by cgoStructGen - DO NOT EDIT
+// Struct definitions generated for C from Go struct definitions
+
+#include <math.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+	typedef double_t meters_t;
+	typedef uint64_t user_id_t;
+
+	typedef int8_t color_t;
+	enum color{
+		color_Red = 0,
+	};
+
+	typedef struct s1{
+		meters_t f1;
+		user_id_t f2;
+		color_t f3;
+	} s1_t;
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+	sbtest.Eq(t, string(data), exp)
+}